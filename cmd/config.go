@@ -0,0 +1,402 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+	cliutils "github.com/Fuabioo/altalune/pkg/cliutls"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Set up and inspect the configuration",
+	Long: `Settings are read, in order of precedence, from flags, JIRA_EPIC_*
+environment variables and config.yaml in the current directory, $HOME/.config
+or /etc. Environment variables are also loaded from every env file found
+among ./.env, ./.env.local, ~/.env and ~/.altalune.env, the earlier ones
+taking precedence.`,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Check that every setting needed to reach Jira is present and valid",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
+		if file := viper.ConfigFileUsed(); file != "" {
+			fmt.Fprintf(out, "Config file: %s\n", file)
+		} else {
+			fmt.Fprintln(out, "Config file: none, using flags and environment")
+		}
+
+		cfg := jiraConfig()
+		problems := jiraProblems(cfg)
+		for _, problem := range problems {
+			fmt.Fprintf(out, "✗ %s\n", problem)
+		}
+		if len(problems) > 0 {
+			return errors.New("the configuration is invalid")
+		}
+
+		if fixtures := viper.GetString("fixtures"); fixtures != "" {
+			fmt.Fprintf(out, "✓ Reading epics from fixtures in %s\n", fixtures)
+			return nil
+		}
+
+		fmt.Fprintf(out, "✓ JIRA %s with %s auth, REST API v%s (%s search)\n", cfg.Host, cfg.Auth, cfg.APIVersion, cfg.SearchAPI)
+		return nil
+	},
+}
+
+var configShowCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Print the effective configuration and where each setting comes from",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		out := cmd.OutOrStdout()
+
+		if file := viper.ConfigFileUsed(); file != "" {
+			fmt.Fprintf(out, "Config file: %s\n", file)
+		} else {
+			fmt.Fprintln(out, "Config file: none")
+		}
+		if len(envFiles) == 0 {
+			fmt.Fprintln(out, "Env files:   none")
+		}
+		for _, file := range envFiles {
+			set := strings.Join(file.Variables, ", ")
+			if set == "" {
+				set = "nothing not already set"
+			}
+			fmt.Fprintf(out, "Env file:    %s (%s)\n", file.Path, set)
+		}
+		fmt.Fprintln(out)
+
+		keys := viper.AllKeys()
+		slices.Sort(keys)
+
+		w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, key := range keys {
+			fmt.Fprintf(w, "%s\t%s\t%s\n", key, settingValue(key), settingSource(key))
+		}
+		return w.Flush()
+	},
+}
+
+// secretKeys are the parts of a setting's key that make it a secret
+var secretKeys = []string{"token", "password", "secret"}
+
+// settingValue is the effective value of the key, with secrets masked
+func settingValue(key string) string {
+	var value string
+	switch viper.Get(key).(type) {
+	case []any, []string:
+		value = strings.Join(viper.GetStringSlice(key), ", ")
+	default:
+		value = viper.GetString(key)
+	}
+
+	switch {
+	case value == "":
+		return "-"
+	case cliutils.Contains(key, secretKeys...):
+		return cliutils.MaskToken(value)
+	}
+	return value
+}
+
+// settingSource tells where the value of the key comes from, following
+// viper's precedence. Flags are looked up among every command's, as viper
+// binds them all whichever command runs.
+func settingSource(key string) string {
+	if flag := commandFlag(rootCmd, key); flag != nil && flag.Changed {
+		return "flag --" + key
+	}
+
+	// Like viper, an empty variable counts as unset
+	if name := envName(key); os.Getenv(name) != "" {
+		for _, file := range envFiles {
+			if slices.Contains(file.Variables, name) {
+				return name + " in " + file.Path
+			}
+		}
+		return "environment " + name
+	}
+
+	if viper.InConfig(key) {
+		if key == "workspace" {
+			return "config file (deprecated, use host)"
+		}
+		return "config file"
+	}
+
+	return "default"
+}
+
+// commandFlag finds the flag named key in cmd or any of its subcommands
+func commandFlag(cmd *cobra.Command, key string) *pflag.Flag {
+	if flag := cmd.Flags().Lookup(key); flag != nil {
+		return flag
+	}
+	for _, sub := range cmd.Commands() {
+		if flag := commandFlag(sub, key); flag != nil {
+			return flag
+		}
+	}
+	return nil
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up the connection to Jira and save it",
+	Long: `Prompts for the Jira host, email and API token, checks that they can
+reach Jira and saves them to $HOME/.config/config.yaml, readable only by you.
+Settings already in that file are kept. Pass --auth bearer for a personal
+access token, or --api-version 2 for Jira Data Center and Server, to save
+those too.`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		path, err := userConfigFile()
+		if err != nil {
+			return err
+		}
+
+		out := cmd.OutOrStdout()
+		prompt := newPrompter(cmd.InOrStdin(), out)
+		current := jiraConfig()
+
+		fmt.Fprintf(out, "Setting up the connection to JIRA, saved to %s\n\n", path)
+
+		settings := map[string]string{}
+		host, err := prompt.ask("JIRA host (e.g. company.atlassian.net)", current.Host, false)
+		if err != nil {
+			return err
+		}
+		settings["host"] = normalizeHost(host)
+
+		if current.Auth == jira.AuthBasic {
+			if settings["email"], err = prompt.ask("Email", current.Email, false); err != nil {
+				return err
+			}
+		}
+
+		label := "API token"
+		if current.Auth == jira.AuthBearer {
+			label = "Personal access token"
+		}
+		if settings["token"], err = prompt.ask(label, current.Token, true); err != nil {
+			return err
+		}
+
+		for _, key := range []string{"auth", "api-version"} {
+			if cmd.Flags().Changed(key) {
+				settings[key] = viper.GetString(key)
+			}
+		}
+
+		for key, value := range settings {
+			viper.Set(key, value)
+		}
+		cfg := jiraConfig()
+
+		fmt.Fprintf(out, "\nConnecting to %s…\n", cfg.Host)
+		ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+		defer cancel()
+		if err := jira.NewClient(cfg).Ping(ctx); err != nil {
+			return fmt.Errorf("could not connect to JIRA, nothing was saved: %w", err)
+		}
+		fmt.Fprintln(out, "✓ Connected")
+
+		if err := saveConfig(path, settings); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "✓ Saved %s\n", path)
+
+		// Warn about what would still take precedence over the saved file
+		if used := viper.ConfigFileUsed(); used != "" && used != path {
+			fmt.Fprintf(out, "! %s is read instead of %s\n", used, path)
+		}
+		for _, key := range slices.Sorted(maps.Keys(settings)) {
+			if name := envName(key); os.Getenv(name) != "" {
+				fmt.Fprintf(out, "! %s is set and overrides the saved %s\n", name, key)
+			}
+		}
+
+		return nil
+	},
+}
+
+// userConfigFile is the config.yaml of the user, the one init writes
+func userConfigFile() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("could not find the home directory: %w", err)
+	}
+	return filepath.Join(home, ".config", "config.yaml"), nil
+}
+
+// normalizeHost turns a pasted Jira URL into its host
+func normalizeHost(host string) string {
+	host = strings.TrimPrefix(host, "https://")
+	host = strings.TrimPrefix(host, "http://")
+	return strings.TrimRight(host, "/")
+}
+
+// saveConfig sets the settings in the YAML file at path, keeping the rest
+// of it, comments included, and dropping the deprecated workspace. The file
+// is only readable by its owner, as it holds the token.
+func saveConfig(path string, settings map[string]string) error {
+	var document yaml.Node
+	data, err := os.ReadFile(path)
+	switch {
+	case err == nil:
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return fmt.Errorf("could not read %s: %w", path, err)
+		}
+	case !errors.Is(err, fs.ErrNotExist):
+		return err
+	}
+
+	if document.Kind == 0 {
+		document = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode}}}
+	}
+	mapping := document.Content[0]
+	if mapping.Kind != yaml.MappingNode {
+		return fmt.Errorf("could not update %s: it isn't a YAML mapping", path)
+	}
+
+	// Keys and values alternate in the mapping's content
+	index := func(key string) int {
+		for i := 0; i < len(mapping.Content); i += 2 {
+			if mapping.Content[i].Value == key {
+				return i
+			}
+		}
+		return -1
+	}
+
+	if i := index("workspace"); i >= 0 {
+		// Its comment is kept on the key that takes its place
+		if i+2 < len(mapping.Content) && mapping.Content[i+2].HeadComment == "" {
+			mapping.Content[i+2].HeadComment = mapping.Content[i].HeadComment
+		}
+		mapping.Content = slices.Delete(mapping.Content, i, i+2)
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(settings)) {
+		value := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: settings[key]}
+		if i := index(key); i >= 0 {
+			value.LineComment = mapping.Content[i+1].LineComment
+			mapping.Content[i+1] = value
+			continue
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Value: key}, value)
+	}
+
+	var buffer strings.Builder
+	encoder := yaml.NewEncoder(&buffer)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, []byte(buffer.String()), 0o600); err != nil {
+		return err
+	}
+	// WriteFile keeps the permissions of a file that already exists
+	return os.Chmod(path, 0o600)
+}
+
+// prompter asks for settings, keeping their current value when nothing is
+// typed.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// fd is the terminal secrets are read from without echo, when the
+	// input is one.
+	fd       uintptr
+	terminal bool
+}
+
+func newPrompter(in io.Reader, out io.Writer) prompter {
+	p := prompter{in: bufio.NewReader(in), out: out}
+	if file, ok := in.(*os.File); ok && term.IsTerminal(file.Fd()) {
+		p.fd, p.terminal = file.Fd(), true
+	}
+	return p
+}
+
+// ask prompts for the setting until it has a value. Secrets are neither
+// echoed nor shown unmasked.
+func (p prompter) ask(label, current string, secret bool) (string, error) {
+	shown := current
+	if secret && current != "" {
+		shown = cliutils.MaskToken(current)
+	}
+
+	for {
+		if shown != "" {
+			fmt.Fprintf(p.out, "%s [%s]: ", label, shown)
+		} else {
+			fmt.Fprintf(p.out, "%s: ", label)
+		}
+
+		answer, err := p.read(secret)
+		if err != nil {
+			return "", fmt.Errorf("could not read the %s: %w", strings.ToLower(label), err)
+		}
+		if answer == "" {
+			answer = current
+		}
+		if answer != "" {
+			return answer, nil
+		}
+	}
+}
+
+func (p prompter) read(secret bool) (string, error) {
+	if secret && p.terminal {
+		answer, err := term.ReadPassword(p.fd)
+		fmt.Fprintln(p.out)
+		return strings.TrimSpace(string(answer)), err
+	}
+
+	line, err := p.in.ReadString('\n')
+	if errors.Is(err, io.EOF) && line != "" {
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
+
+func init() {
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configShowCmd)
+	configCmd.AddCommand(configValidateCmd)
+	rootCmd.AddCommand(configCmd)
+}
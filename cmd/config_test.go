@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		existing string
+		settings map[string]string
+		want     string
+	}{
+		{
+			name:     "new file",
+			settings: map[string]string{"host": "company.atlassian.net", "token": "abcd1234"},
+			want:     "host: company.atlassian.net\ntoken: abcd1234\n",
+		},
+		{
+			name: "keeps the rest and its comments",
+			existing: `# Jira connection
+workspace: old.atlassian.net
+email: bo@example.com # the account
+token: old # rotated monthly
+# Web server
+server-port: 8080
+`,
+			settings: map[string]string{"host": "company.atlassian.net", "token": "abcd1234"},
+			want: `# Jira connection
+email: bo@example.com # the account
+token: abcd1234 # rotated monthly
+# Web server
+server-port: 8080
+host: company.atlassian.net
+`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), ".config", "config.yaml")
+			if tt.existing != "" {
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				// Readable by everyone until saved
+				if err := os.WriteFile(path, []byte(tt.existing), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			if err := saveConfig(path, tt.settings); err != nil {
+				t.Fatalf("saveConfig() error = %v", err)
+			}
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := string(data); got != tt.want {
+				t.Errorf("saved config:\n%s\nwant:\n%s", got, tt.want)
+			}
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if mode := info.Mode().Perm(); mode != 0o600 {
+				t.Errorf("saved config mode = %v, want %v", mode, os.FileMode(0o600))
+			}
+		})
+	}
+}
+
+func TestSaveConfigRejectsNonMapping(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("- host\n- token\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveConfig(path, map[string]string{"host": "company.atlassian.net"}); err == nil {
+		t.Error("saveConfig() of a YAML list succeeded, want an error")
+	}
+}
@@ -0,0 +1,500 @@
+package cmd
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type (
+	checkStatus int
+
+	// checkResult is the outcome of a doctor check. Failures come with a
+	// hint on how to fix them.
+	checkResult struct {
+		status checkStatus
+		detail string
+		hint   string
+	}
+
+	doctorCheck struct {
+		name string
+		run  func(ctx context.Context) checkResult
+	}
+
+	// doctor runs the checks against the configured Jira
+	doctor struct {
+		out      io.Writer
+		timeout  time.Duration
+		failures int
+
+		cfg     jira.Config
+		client  *jira.Client
+		proxy   *url.URL
+		project string
+		epic    string
+	}
+)
+
+const (
+	checkPassed checkStatus = iota
+	checkFailed
+	checkSkipped
+)
+
+func passed(format string, args ...any) checkResult {
+	return checkResult{status: checkPassed, detail: fmt.Sprintf(format, args...)}
+}
+
+func failed(detail string, hint string) checkResult {
+	return checkResult{status: checkFailed, detail: detail, hint: hint}
+}
+
+func skipped(reason string) checkResult {
+	return checkResult{status: checkSkipped, detail: reason}
+}
+
+var doctorCmd = &cobra.Command{
+	Use:   "doctor",
+	Short: "Diagnose the connection to Jira",
+	Long: `Checks, one step at a time, everything Altalune needs: the
+configuration, reaching the Jira host (DNS, TCP and TLS), authenticating,
+browsing a project, searching issues, finding the story point field and the
+web interface embedded in the binary. Every failure comes with a hint on how
+to fix it.
+
+The project checked is --project, or the one of the first epic listed under
+epics in config.yaml.`,
+	Example: `  altalune doctor
+  altalune doctor --project ABC --verbose`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		project, _ := cmd.Flags().GetString("project")
+		timeout, _ := cmd.Flags().GetDuration("timeout")
+
+		cfg := jiraConfig()
+		// A check should fail fast rather than wait on retries
+		cfg.Retry.MaxAttempts = 1
+
+		d := &doctor{
+			out:     cmd.OutOrStdout(),
+			timeout: timeout,
+			cfg:     cfg,
+			client:  jira.NewClient(cfg),
+			proxy:   jiraProxy(cfg),
+		}
+		d.epic, d.project = sampleEpic(project)
+
+		ctx := cmd.Context()
+		if d.run(ctx, []doctorCheck{{"Configuration", d.checkConfig}}, true) {
+			if fixtures := viper.GetString("fixtures"); fixtures != "" {
+				d.skip("reading epics from fixtures instead of Jira",
+					"DNS", "TCP", "TLS", "Authentication", "Browse permission", "Search API", "Story points")
+			} else {
+				d.run(ctx, []doctorCheck{
+					{"DNS", d.checkDNS},
+					{"TCP", d.checkTCP},
+					{"TLS", d.checkTLS},
+				}, true)
+
+				// The requests run even when connecting by hand failed, as
+				// the client may still get through.
+				if d.run(ctx, []doctorCheck{{"Authentication", d.checkAuth}}, true) {
+					d.run(ctx, []doctorCheck{
+						{"Browse permission", d.checkBrowse},
+						{"Search API", d.checkSearch},
+						{"Story points", d.checkStoryPoints},
+					}, false)
+				} else {
+					d.skip("fix the failure above first", "Browse permission", "Search API", "Story points")
+				}
+			}
+		}
+		d.run(ctx, []doctorCheck{{"Web interface", checkAssets}}, false)
+
+		if d.failures > 0 {
+			return fmt.Errorf("%d %s failed", d.failures, plural(d.failures, "check", "checks"))
+		}
+		return nil
+	},
+}
+
+func plural(n int, one string, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
+
+// sampleEpic picks the project, and the epic if any, the checks that need
+// one look at: the given project, or the one of the first saved epic.
+func sampleEpic(project string) (string, string) {
+	saved := append(viper.GetStringSlice("epics"), viper.GetStringSlice("snapshot-epics")...)
+	if project != "" {
+		for _, epic := range saved {
+			if key, _, _ := strings.Cut(epic, "-"); strings.EqualFold(key, project) {
+				return epic, project
+			}
+		}
+		return "", project
+	}
+	if len(saved) == 0 {
+		return "", ""
+	}
+	key, _, _ := strings.Cut(saved[0], "-")
+	return saved[0], key
+}
+
+// run runs the checks in order and tells whether they all passed. When
+// chained, a failure skips the checks after it, which depend on it.
+func (d *doctor) run(ctx context.Context, checks []doctorCheck, chained bool) bool {
+	ok := true
+	for _, check := range checks {
+		if !ok && chained {
+			d.print(check.name, skipped("fix the failure above first"))
+			continue
+		}
+
+		checkCtx, cancel := context.WithTimeout(ctx, d.timeout)
+		result := check.run(checkCtx)
+		cancel()
+
+		d.print(check.name, result)
+		if result.status == checkFailed {
+			ok = false
+		}
+	}
+	return ok
+}
+
+func (d *doctor) skip(reason string, names ...string) {
+	for _, name := range names {
+		d.print(name, skipped(reason))
+	}
+}
+
+func (d *doctor) print(name string, result checkResult) {
+	switch result.status {
+	case checkPassed:
+		fmt.Fprintf(d.out, "✓ %-18s %s\n", name, result.detail)
+	case checkFailed:
+		d.failures++
+		fmt.Fprintf(d.out, "✗ %-18s %s\n", name, result.detail)
+		if result.hint != "" {
+			fmt.Fprintf(d.out, "  %-18s → %s\n", "", result.hint)
+		}
+	case checkSkipped:
+		fmt.Fprintf(d.out, "- %-18s skipped: %s\n", name, result.detail)
+	}
+}
+
+func (d *doctor) checkConfig(ctx context.Context) checkResult {
+	if problems := jiraProblems(d.cfg); len(problems) > 0 {
+		return failed(strings.Join(problems, "; "),
+			"run altalune config init, or altalune config show to see where each setting comes from")
+	}
+
+	if fixtures := viper.GetString("fixtures"); fixtures != "" {
+		return passed("reading epics from fixtures in %s", fixtures)
+	}
+
+	source := viper.ConfigFileUsed()
+	if source == "" {
+		source = "flags and environment"
+	}
+	return passed("%s, %s auth, REST API v%s, from %s", d.cfg.Host, d.cfg.Auth, d.cfg.APIVersion, source)
+}
+
+// jiraProxy is the proxy the client reaches Jira through, as set by
+// HTTPS_PROXY, HTTP_PROXY and NO_PROXY, or nil when it connects directly.
+func jiraProxy(cfg jira.Config) *url.URL {
+	req, err := http.NewRequest(http.MethodGet, cfg.BaseURL, nil)
+	if err != nil {
+		return nil
+	}
+
+	proxy, err := http.ProxyFromEnvironment(req)
+	if err != nil {
+		return nil
+	}
+	return proxy
+}
+
+// endpoint is the host and port the client connects to, the proxy's when
+// there is one, and whether it speaks TLS to Jira.
+func (d *doctor) endpoint() (string, string, bool) {
+	u, err := url.Parse(d.cfg.BaseURL)
+	if err != nil {
+		u = &url.URL{Scheme: "https", Host: d.cfg.Host}
+	}
+	secure := u.Scheme != "http"
+
+	if d.proxy != nil {
+		u = d.proxy
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "443"
+		if u.Scheme == "http" {
+			port = "80"
+		}
+	}
+	return u.Hostname(), port, secure
+}
+
+// via names the proxy the checks went through, if any
+func (d *doctor) via() string {
+	if d.proxy == nil {
+		return ""
+	}
+	return " (proxy " + d.proxy.Redacted() + ")"
+}
+
+func (d *doctor) checkDNS(ctx context.Context) checkResult {
+	host, _, _ := d.endpoint()
+
+	addresses, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		if d.proxy != nil {
+			return failed(err.Error(), "check the proxy set by HTTPS_PROXY or HTTP_PROXY, or list the Jira host in NO_PROXY")
+		}
+		return failed(err.Error(),
+			"check host for typos (it should look like company.atlassian.net), and that this machine can resolve it: VPN, DNS or /etc/hosts")
+	}
+	if len(addresses) > 3 {
+		addresses = append(addresses[:3], "…")
+	}
+	return passed("%s resolves to %s%s", host, strings.Join(addresses, ", "), d.via())
+}
+
+func (d *doctor) checkTCP(ctx context.Context) checkResult {
+	host, port, _ := d.endpoint()
+	address := net.JoinHostPort(host, port)
+
+	start := time.Now()
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		if d.proxy != nil {
+			return failed(err.Error(), "check that the proxy set by HTTPS_PROXY or HTTP_PROXY is up")
+		}
+		return failed(err.Error(),
+			fmt.Sprintf("a firewall, proxy or VPN may be blocking port %s, or Jira is down", port))
+	}
+	conn.Close()
+
+	return passed("connected to %s in %s%s", address, time.Since(start).Round(time.Millisecond), d.via())
+}
+
+func (d *doctor) checkTLS(ctx context.Context) checkResult {
+	host, port, secure := d.endpoint()
+	switch {
+	case !secure:
+		return passed("plain HTTP, nothing to check")
+	case d.proxy != nil:
+		return skipped("Jira is reached through the proxy, the requests below check TLS")
+	}
+
+	dialer := tls.Dialer{Config: &tls.Config{ServerName: host}}
+	conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return failed(err.Error(),
+			"a proxy or a private certificate authority may be in the way: add its certificate to the system trust store, or point SSL_CERT_FILE at it")
+	}
+	defer conn.Close()
+
+	state := conn.(*tls.Conn).ConnectionState()
+	certificate := state.PeerCertificates[0]
+	return passed("%s, certificate issued by %s, valid until %s",
+		tls.VersionName(state.Version),
+		certificate.Issuer.CommonName,
+		certificate.NotAfter.Format(time.DateOnly),
+	)
+}
+
+func (d *doctor) checkAuth(ctx context.Context) checkResult {
+	user, err := d.client.Myself(ctx)
+	if err != nil {
+		var apiErr *jira.APIError
+		if !errors.As(err, &apiErr) {
+			return failed(err.Error(), "check that host points at Jira")
+		}
+
+		switch apiErr.Kind() {
+		case jira.ErrorKindAuth:
+			if d.cfg.Auth == jira.AuthBearer {
+				return failed(err.Error(),
+					"check the personal access token; Jira Cloud needs --auth basic with an email and API token instead")
+			}
+			return failed(err.Error(),
+				"check email and token: API tokens are created at https://id.atlassian.com/manage-profile/security/api-tokens, and Data Center needs --auth bearer")
+		case jira.ErrorKindNotFound:
+			return failed(err.Error(),
+				fmt.Sprintf("no REST API at %s: check api-version (2 for Data Center and Server) and base-path", d.cfg.BasePath))
+		default:
+			return failed(err.Error(), "check that host points at Jira")
+		}
+	}
+
+	who := user.DisplayName
+	if user.EmailAddress != "" {
+		who += " <" + user.EmailAddress + ">"
+	}
+	return passed("signed in as %s", who)
+}
+
+func (d *doctor) checkBrowse(ctx context.Context) checkResult {
+	if d.project == "" {
+		return skipped("no project to check, pass --project or list epics in config.yaml")
+	}
+
+	ok, err := d.client.HasPermission(ctx, d.project, "BROWSE_PROJECTS")
+	var apiErr *jira.APIError
+	switch {
+	case errors.As(err, &apiErr) && apiErr.Kind() == jira.ErrorKindNotFound:
+		return failed(err.Error(),
+			fmt.Sprintf("check that %s is the key of a project, and ask a Jira admin for access to it", d.project))
+	case err != nil:
+		return failed(err.Error(), "check that host points at Jira")
+	case !ok:
+		return failed(fmt.Sprintf("no Browse Projects permission on %s", d.project),
+			fmt.Sprintf("ask a Jira admin for the Browse Projects permission on %s", d.project))
+	}
+
+	return passed("can browse %s", d.project)
+}
+
+// checkSearch tries both search endpoints with the query Altalune would
+// send, so that a failing one can be swapped for the other.
+func (d *doctor) checkSearch(ctx context.Context) checkResult {
+	query := "created >= -1w"
+	switch {
+	case d.epic != "":
+		var err error
+		if query, err = (jira.ListEpicRequest{EpicID: d.epic}).Query(); err != nil {
+			return failed(err.Error(), "pass the key of an epic, such as ABC-123")
+		}
+	case d.project != "":
+		query = fmt.Sprintf("project = %s", d.project)
+	}
+
+	paths := map[jira.SearchAPI]string{
+		jira.SearchAPIJQL:    "/search/jql",
+		jira.SearchAPILegacy: "/search",
+	}
+	errs := map[jira.SearchAPI]error{}
+	for api := range paths {
+		cfg := d.cfg
+		cfg.SearchAPI = api
+		_, errs[api] = jira.NewClient(cfg).ListEpicIssues(ctx, jira.ListEpicRequest{
+			JQL:      query,
+			PageSize: 1,
+			Fields:   []string{"summary"},
+		})
+	}
+
+	configured := d.cfg.SearchAPI
+	other := jira.SearchAPILegacy
+	if configured == jira.SearchAPILegacy {
+		other = jira.SearchAPIJQL
+	}
+
+	otherState := "answers too"
+	if errs[other] != nil {
+		otherState = "doesn't: " + searchFailure(errs[other])
+	}
+
+	switch {
+	case errs[configured] == nil:
+		return passed("%s answers %q (%s %s)", paths[configured], query, paths[other], otherState)
+	case errs[other] == nil:
+		return failed(fmt.Sprintf("%s fails: %s, but %s answers", paths[configured], searchFailure(errs[configured]), paths[other]),
+			fmt.Sprintf("set search-api to %s", other))
+	default:
+		return failed(fmt.Sprintf("%s fails: %s", paths[configured], errs[configured]),
+			fmt.Sprintf("check that you can search %q in Jira", query))
+	}
+}
+
+// searchFailure is the status a search endpoint failed with, or the error
+// when it didn't answer.
+func searchFailure(err error) string {
+	var apiErr *jira.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Sprintf("status %d", apiErr.StatusCode)
+	}
+	return err.Error()
+}
+
+func (d *doctor) checkStoryPoints(ctx context.Context) checkResult {
+	fields, err := d.client.ListFields(ctx)
+	if err != nil {
+		return failed(err.Error(), "check that host points at Jira")
+	}
+
+	hint := fmt.Sprintf("set story-point-field to the ID or name of the field your projects estimate with; until then %s are tried",
+		strings.Join(d.cfg.StoryPointFields, ", "))
+
+	override := strings.TrimSpace(d.cfg.StoryPointField)
+	if strings.HasPrefix(override, "customfield_") {
+		for _, field := range fields {
+			if field.ID == override {
+				return passed("%s (%s), set by story-point-field", field.Name, field.ID)
+			}
+		}
+		return failed(fmt.Sprintf("story-point-field %s isn't a field of this Jira", override), hint)
+	}
+
+	var names []string
+	if override != "" {
+		names = []string{override}
+	}
+	field, ok := jira.FindStoryPointField(fields, names...)
+	if !ok {
+		if override != "" {
+			return failed(fmt.Sprintf("no field named %q", override), hint)
+		}
+		return failed(`no field named "Story Points" or "Story point estimate"`, hint)
+	}
+
+	if field.Schema == nil || field.Schema.Type != "number" {
+		return failed(fmt.Sprintf("%s (%s) isn't a number field", field.Name, field.ID), hint)
+	}
+	return passed("%s (%s)", field.Name, field.ID)
+}
+
+func checkAssets(ctx context.Context) checkResult {
+	if _, err := fs.Stat(assets, "index.html"); err != nil {
+		return failed("frontend/dist/index.html isn't embedded in this binary",
+			"build the frontend before altalune (just build does both)")
+	}
+
+	files := 0
+	fs.WalkDir(assets, ".", func(path string, entry fs.DirEntry, err error) error {
+		if err == nil && !entry.IsDir() {
+			files++
+		}
+		return nil
+	})
+	return passed("%d %s embedded from frontend/dist", files, plural(files, "file", "files"))
+}
+
+func init() {
+	doctorCmd.Flags().String("project", "", "Project to check permissions and search on (defaults to the one of the first saved epic)")
+	doctorCmd.Flags().Duration("timeout", 10*time.Second, "Time allowed for each check")
+	rootCmd.AddCommand(doctorCmd)
+}
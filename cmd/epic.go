@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/report"
+
+	"github.com/charmbracelet/log"
+	"github.com/charmbracelet/x/term"
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+var epicCmd = &cobra.Command{
+	Use:   "epic <epic>",
+	Short: "Print a progress report of an epic",
+	Long: `Print the progress of an epic, its status and type breakdowns, the work of
+each assignee and the issues waiting on unfinished blockers. Use --output json
+or yaml to script against the same data.`,
+	Example: `  altalune epic ABC-123
+  altalune epic ABC-123 --output json | jq '.stats.percentage'`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: reportPreRun,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		output, _ := cmd.Flags().GetString("output")
+		if output != "table" && output != "json" && output != "yaml" {
+			return fmt.Errorf("unknown output %q, expected table, json or yaml", output)
+		}
+
+		hops, err := hopsFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		cfg := jiraConfig()
+		source, err := issueSource(cfg)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		key := args[0]
+		loader := newLoader(source, cfg)
+
+		result, linked, err := fetchEpic(ctx, loader, key, hops)
+		if err != nil {
+			return err
+		}
+		points := loader.Points(ctx)
+
+		epic := report.New(key, result.Issues, points, linked, result.FetchedAt)
+		if epic.Summary == "" {
+			epic.Summary = epicSummary(cmd, source, key)
+		}
+
+		out := cmd.OutOrStdout()
+		switch output {
+		case "json":
+			encoder := json.NewEncoder(out)
+			encoder.SetIndent("", "  ")
+			return encoder.Encode(epic)
+		case "yaml":
+			return encodeYAML(out, epic)
+		default:
+			_, err := io.WriteString(out, report.Render(epic, terminalWidth()))
+			return err
+		}
+	},
+}
+
+// epicSummary fetches the summary of the epic itself, which isn't one of its
+// own issues. The report goes on without it when it can't be fetched.
+func epicSummary(cmd *cobra.Command, source jira.IssueSource, key string) string {
+	issues, err := jira.ListAllEpicIssues(cmd.Context(), source, jira.ListEpicRequest{
+		Keys:     []string{key},
+		PageSize: 1,
+		Fields:   []string{"summary"},
+	})
+	if err != nil || len(issues) == 0 {
+		log.Debug("Could not fetch the epic's summary", "epic", key, "err", err)
+		return ""
+	}
+	return issues[0].Fields.Summary
+}
+
+// encodeYAML writes v as YAML with the same field names as its JSON
+// encoding, since JSON is valid YAML to decode from.
+func encodeYAML(w io.Writer, v any) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var document yaml.Node
+	if err := yaml.Unmarshal(body, &document); err != nil {
+		return err
+	}
+	blockStyle(&document)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&document); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// blockStyle drops the flow style and quoting decoding JSON leaves on the
+// nodes, so they are written as idiomatic YAML.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
+
+func terminalWidth() int {
+	width, _, err := term.GetSize(os.Stdout.Fd())
+	if err != nil || width <= 0 {
+		return 80
+	}
+	return width
+}
+
+func init() {
+	epicCmd.Flags().StringP("output", "o", "table", "Output format (table, json, yaml)")
+	epicCmd.Flags().Int("hops", 0, "Also follow blockers outside the epic up to this many links away")
+
+	rootCmd.AddCommand(epicCmd)
+}
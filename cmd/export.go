@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"os"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/spf13/cobra"
+)
+
+var exportCmd = &cobra.Command{
+	Use:   "export <epic>",
+	Short: "Export the dependency graph of an epic",
+	Long: `Export the dependency graph of an epic as JSON, Graphviz DOT, Mermaid or
+GraphML. Nodes are colored by status and labelled with their story points.`,
+	Example: `  altalune export ABC-123 --format dot | dot -Tsvg > ABC-123.svg
+  altalune export ABC-123 --format mermaid --hops 1 --output ABC-123.mmd`,
+	Args:    cobra.ExactArgs(1),
+	PreRunE: reportPreRun,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		name, _ := cmd.Flags().GetString("format")
+		format, err := jira.ParseGraphFormat(name)
+		if err != nil {
+			return err
+		}
+
+		hops, err := hopsFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		cfg := jiraConfig()
+		source, err := issueSource(cfg)
+		if err != nil {
+			return err
+		}
+
+		ctx := cmd.Context()
+		key := args[0]
+		loader := newLoader(source, cfg)
+
+		result, linked, err := fetchEpic(ctx, loader, key, hops)
+		if err != nil {
+			return err
+		}
+		points := loader.Points(ctx)
+
+		graph := jira.BuildGraph(result.Issues, key, points, linked)
+
+		path, _ := cmd.Flags().GetString("output")
+		if path == "" || path == "-" {
+			return jira.WriteGraph(cmd.OutOrStdout(), graph, format)
+		}
+
+		file, err := os.Create(path)
+		if err != nil {
+			return err
+		}
+		if err := jira.WriteGraph(file, graph, format); err != nil {
+			file.Close()
+			return err
+		}
+		return file.Close()
+	},
+}
+
+func init() {
+	exportCmd.Flags().StringP("format", "f", string(jira.GraphDOT), "Graph format (json, dot, mermaid, graphml)")
+	exportCmd.Flags().Int("hops", 0, "Also export the issues outside the epic up to this many links away")
+	exportCmd.Flags().StringP("output", "o", "", "File to write the graph to (stdout when empty)")
+
+	rootCmd.AddCommand(exportCmd)
+}
@@ -0,0 +1,161 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/Fuabioo/altalune/internal/epics"
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// jiraConfig builds the Jira client settings from the configuration
+func jiraConfig() jira.Config {
+	cfg := jira.Config{
+		Host:            jiraHost(),
+		Email:           viper.GetString("email"),
+		Token:           viper.GetString("token"),
+		Auth:            jira.AuthMode(viper.GetString("auth")),
+		APIVersion:      viper.GetString("api-version"),
+		BasePath:        viper.GetString("base-path"),
+		SearchAPI:       jira.SearchAPI(viper.GetString("search-api")),
+		StoryPointField: viper.GetString("story-point-field"),
+		Retry: jira.RetryPolicy{
+			MaxAttempts:    viper.GetInt("retry-max-attempts"),
+			InitialBackoff: viper.GetDuration("retry-initial-backoff"),
+			MaxBackoff:     viper.GetDuration("retry-max-backoff"),
+		},
+		SuperDebug: viper.GetBool("super-debug"),
+	}
+
+	return cfg.WithDefaults()
+}
+
+// jiraHost reads host, falling back to workspace, which older versions
+// documented for the same setting.
+func jiraHost() string {
+	if host := viper.GetString("host"); host != "" {
+		return host
+	}
+
+	host := viper.GetString("workspace")
+	if host != "" {
+		warnWorkspace.Do(func() {
+			log.Warn("The workspace setting is deprecated, set host instead", "host", host)
+		})
+	}
+	return host
+}
+
+var warnWorkspace sync.Once
+
+// requireJira fails when a setting needed to reach Jira is missing or
+// invalid, listing every problem at once along with where it can be set.
+func requireJira(cmd *cobra.Command, args []string) error {
+	problems := jiraProblems(jiraConfig())
+	if len(problems) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("the JIRA configuration is incomplete: %s", strings.Join(problems, "; "))
+}
+
+// jiraProblems lists what is wrong with the Jira settings. Nothing is
+// needed to read epics from fixtures.
+func jiraProblems(cfg jira.Config) []string {
+	if viper.GetString("fixtures") != "" {
+		return nil
+	}
+
+	var problems []string
+	missing := func(key string) {
+		problems = append(problems, fmt.Sprintf("%s is missing (set --%s, %s or %s in config.yaml)", key, key, envName(key), key))
+	}
+
+	if cfg.Host == "" {
+		missing("host")
+	}
+	if cfg.Auth == jira.AuthBasic && cfg.Email == "" {
+		missing("email")
+	}
+	if cfg.Token == "" {
+		missing("token")
+	}
+
+	if !slices.Contains([]jira.AuthMode{jira.AuthBasic, jira.AuthBearer}, cfg.Auth) {
+		problems = append(problems, fmt.Sprintf("auth %q is invalid, expected basic or bearer", cfg.Auth))
+	}
+	if cfg.APIVersion != "2" && cfg.APIVersion != "3" {
+		problems = append(problems, fmt.Sprintf("api-version %q is invalid, expected 2 or 3", cfg.APIVersion))
+	}
+	if !slices.Contains([]jira.SearchAPI{jira.SearchAPIJQL, jira.SearchAPILegacy}, cfg.SearchAPI) {
+		problems = append(problems, fmt.Sprintf("search-api %q is invalid, expected jql or legacy", cfg.SearchAPI))
+	}
+
+	return problems
+}
+
+// envName is the environment variable a configuration key is read from
+func envName(key string) string {
+	return "JIRA_EPIC_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+}
+
+// issueSource returns a Jira client, or a MemorySource when --fixtures is
+// set.
+func issueSource(cfg jira.Config) (jira.IssueSource, error) {
+	fixtures := viper.GetString("fixtures")
+	if fixtures == "" {
+		return jira.NewClient(cfg), nil
+	}
+
+	log.Debug("Reading epics from fixtures instead of Jira", "dir", fixtures)
+	return jira.LoadMemorySource(os.DirFS(fixtures))
+}
+
+// newLoader loads epics the way the server does, through the same cache
+func newLoader(source jira.IssueSource, cfg jira.Config) *epics.Loader {
+	return epics.NewLoader(source, jira.NewStoryPointResolver(source, cfg), epics.Config{
+		PageSize:    viper.GetUint("page-size"),
+		Concurrency: viper.GetInt("fetch-concurrency"),
+		Timeout:     viper.GetDuration("fetch-timeout"),
+		CacheTTL:    viper.GetDuration("cache-ttl"),
+		CacheStale:  viper.GetDuration("cache-stale"),
+	})
+}
+
+// hopsFlag reads --hops, how many links away from the epic issues outside of
+// it are fetched.
+func hopsFlag(cmd *cobra.Command) (int, error) {
+	hops, _ := cmd.Flags().GetInt("hops")
+	if hops < 0 || hops > epics.MaxHops {
+		return 0, fmt.Errorf("invalid hops %d, expected 0 to %d", hops, epics.MaxHops)
+	}
+	return hops, nil
+}
+
+// fetchEpic loads the issues of the epic and, with hops, the issues outside
+// of it they link to.
+func fetchEpic(ctx context.Context, loader *epics.Loader, key string, hops int) (epics.Result, []*model.Ticket, error) {
+	return loader.EpicWithLinks(ctx, epics.Query{
+		Key:    key,
+		Fields: jira.DefaultFields(loader.Points(ctx)),
+	}, hops)
+}
+
+// reportPreRun prepares the commands whose output is the report itself:
+// they only log warnings, unless verbose, so logs don't get in its way.
+func reportPreRun(cmd *cobra.Command, args []string) error {
+	if !viper.GetBool("verbose") {
+		log.SetLevel(log.WarnLevel)
+	}
+
+	return requireJira(cmd, args)
+}
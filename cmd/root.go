@@ -3,8 +3,9 @@ package cmd
 import (
 	"context"
 	"io/fs"
+	"strings"
+	"time"
 
-	"github.com/Fuabioo/altalune/internal/server"
 	cliutils "github.com/Fuabioo/altalune/pkg/cliutls"
 
 	"github.com/charmbracelet/fang"
@@ -13,47 +14,32 @@ import (
 	"github.com/spf13/viper"
 )
 
-var assets fs.FS
+var (
+	assets fs.FS
+	// envFiles are the env files loaded before running the command
+	envFiles []cliutils.EnvFile
+)
 
 var rootCmd = &cobra.Command{
 	Use:   "altalune",
 	Short: "Navigate your epics among the stars",
-	PreRun: func(cmd *cobra.Command, args []string) {
+	Long: `Navigate your epics among the stars: track the progress, dependencies and
+forecasts of Jira epics from the web interface (altalune serve) or the
+terminal (altalune epic, altalune tui).`,
+	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+		envFiles = cliutils.LoadEnvFiles()
+
 		if viper.GetBool("verbose") {
 			log.SetLevel(log.DebugLevel)
 		}
-
-		cliutils.LoadEnvFiles()
-	},
-	Run: func(cmd *cobra.Command, args []string) {
-
-		// Start HTTP server
-		srv, err := server.NewServer(
-			server.ServerAssets(assets),
-			server.ServerAddress(
-				viper.GetString("server-host"),
-				viper.GetUint("server-port"),
-			),
-			server.ServerJira(
-				viper.GetString("workspace"),
-				viper.GetString("email"),
-				viper.GetString("token"),
-			),
-			server.ServerSuperDebug(viper.GetBool("super-debug")),
-		)
-		if err != nil {
-			log.Fatal(err)
-		}
-		defer srv.Close()
-
-		if err := srv.Start(); err != nil {
-			log.Fatal(err)
-		}
 	},
 }
 
 func initConfig() {
 	viper.SetEnvPrefix("JIRA_EPIC")
+	// Keys are dashed, environment variables use underscores:
+	// server-port is read from JIRA_EPIC_SERVER_PORT.
+	viper.SetEnvKeyReplacer(strings.NewReplacer("-", "_"))
 	viper.SetConfigName("config")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
@@ -71,26 +57,58 @@ func initConfig() {
 func init() {
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.Flags().String("host", "", "JIRA host")
-	rootCmd.Flags().String("email", "", "JIRA email")
-	rootCmd.Flags().String("token", "", "JIRA token")
-	rootCmd.Flags().String("server-host", "0.0.0.0", "Server host")
-	rootCmd.Flags().Uint("server-port", 3002, "Server port")
-	rootCmd.Flags().Bool("verbose", false, "Verbose logging")
-	rootCmd.Flags().Bool("super-debug", false, "Super debug logging")
-
-	viper.BindPFlag("host", rootCmd.Flags().Lookup("host"))
-	viper.BindPFlag("email", rootCmd.Flags().Lookup("email"))
-	viper.BindPFlag("token", rootCmd.Flags().Lookup("token"))
-	viper.BindPFlag("server-host", rootCmd.Flags().Lookup("server-host"))
-	viper.BindPFlag("server-port", rootCmd.Flags().Lookup("server-port"))
-	viper.BindPFlag("verbose", rootCmd.Flags().Lookup("verbose"))
-	viper.BindPFlag("super-debug", rootCmd.Flags().Lookup("super-debug"))
+	// Settings shared by every command talking to Jira
+	flags := rootCmd.PersistentFlags()
+	flags.String("host", "", "JIRA host (e.g. company.atlassian.net)")
+	flags.String("email", "", "JIRA email")
+	flags.String("token", "", "JIRA token")
+	flags.String("auth", "basic", "JIRA auth mode (basic for email+token, bearer for a personal access token)")
+	flags.String("api-version", "3", "JIRA REST API version (3 for Cloud, 2 for Data Center/Server)")
+	flags.String("base-path", "", "JIRA REST API base path (default /rest/api/<api-version>)")
+	flags.String("search-api", "", "JIRA search API (jql, legacy), defaults to jql on API v3 and legacy on v2")
+	flags.String("story-point-field", "", "JIRA story point field ID or name (discovered when empty)")
+	flags.Duration("cache-ttl", 2*time.Minute, "How long fetched epics are served from the cache")
+	flags.Duration("cache-stale", 10*time.Minute, "How long expired epics are served while being refetched")
+	flags.Uint("page-size", 50, "Issues fetched per JIRA search page")
+	flags.Int("fetch-concurrency", 4, "JIRA search pages fetched in parallel")
+	flags.Duration("fetch-timeout", 30*time.Second, "Time allowed to fetch a whole epic")
+	flags.Int("retry-max-attempts", 4, "Maximum attempts per JIRA request")
+	flags.Duration("retry-initial-backoff", 500*time.Millisecond, "Initial backoff between JIRA retries")
+	flags.Duration("retry-max-backoff", 10*time.Second, "Maximum backoff between JIRA retries")
+	flags.String("fixtures", "", "Read epics from a directory of JSON fixtures instead of JIRA")
+	flags.Bool("verbose", false, "Verbose logging")
+	flags.Bool("super-debug", false, "Super debug logging")
+
+	for _, key := range []string{
+		"host",
+		"email",
+		"token",
+		"auth",
+		"api-version",
+		"base-path",
+		"search-api",
+		"story-point-field",
+		"cache-ttl",
+		"cache-stale",
+		"page-size",
+		"fetch-concurrency",
+		"fetch-timeout",
+		"retry-max-attempts",
+		"retry-initial-backoff",
+		"retry-max-backoff",
+		"fixtures",
+		"verbose",
+		"super-debug",
+	} {
+		viper.BindPFlag(key, flags.Lookup(key))
+	}
 }
 
-func Execute(staticFiles fs.FS) {
+func Execute(staticFiles fs.FS, buildVersion string) {
 	assets = getFileSystem(staticFiles)
-	if err := fang.Execute(context.Background(), rootCmd); err != nil {
+	version = buildVersion
+
+	if err := fang.Execute(context.Background(), rootCmd, fang.WithVersion(versionString())); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"github.com/Fuabioo/altalune/internal/server"
+
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var serveCmd = &cobra.Command{
+	Use:   "serve",
+	Short: "Start the web interface",
+	Long: `Start the web interface and its API, serving epics fetched from Jira or,
+with --fixtures, from a directory of JSON files.`,
+	Example: `  altalune serve --verbose
+  altalune serve --server-port 8080 --snapshot-db snapshots.db`,
+	Args:    cobra.NoArgs,
+	PreRunE: requireJira,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg := jiraConfig()
+		source, err := issueSource(cfg)
+		if err != nil {
+			return err
+		}
+		if viper.GetString("fixtures") != "" {
+			log.Warn("Serving epics from fixtures instead of Jira", "dir", viper.GetString("fixtures"))
+		}
+
+		srv, err := server.NewServer(
+			server.ServerAssets(assets),
+			server.ServerAddress(
+				viper.GetString("server-host"),
+				viper.GetUint("server-port"),
+			),
+			server.ServerJira(cfg.Host, cfg.Email, cfg.Token),
+			server.ServerJiraAuth(string(cfg.Auth)),
+			server.ServerJiraAPI(cfg.APIVersion, cfg.BasePath),
+			server.ServerSearchAPI(string(cfg.SearchAPI)),
+			server.ServerStoryPointField(cfg.StoryPointField),
+			server.ServerJiraRetry(cfg.Retry),
+			server.ServerIssueSource(source),
+			server.ServerCache(
+				viper.GetDuration("cache-ttl"),
+				viper.GetDuration("cache-stale"),
+			),
+			server.ServerSnapshots(
+				viper.GetString("snapshot-db"),
+				viper.GetDuration("snapshot-interval"),
+				viper.GetStringSlice("snapshot-epics"),
+			),
+			server.ServerForecast(
+				viper.GetString("forecast-jql"),
+				viper.GetInt("forecast-weeks"),
+				viper.GetInt("forecast-trials"),
+			),
+			server.ServerFetch(
+				viper.GetUint("page-size"),
+				viper.GetInt("fetch-concurrency"),
+				viper.GetDuration("fetch-timeout"),
+			),
+			server.ServerSuperDebug(cfg.SuperDebug),
+		)
+		if err != nil {
+			return err
+		}
+		defer srv.Close()
+
+		return srv.Start()
+	},
+}
+
+func init() {
+	flags := serveCmd.Flags()
+	flags.String("server-host", "0.0.0.0", "Server host")
+	flags.Uint("server-port", 3002, "Server port")
+	flags.String("snapshot-db", "", "Database file recording epic snapshots (disabled when empty)")
+	flags.Duration("snapshot-interval", 0, "How often to snapshot --snapshot-epics (disabled when 0)")
+	flags.StringSlice("snapshot-epics", nil, "Epics snapshotted every --snapshot-interval")
+	flags.String("forecast-jql", "", "JQL of the issues forecasts take their throughput from (the epic itself when empty)")
+	flags.Int("forecast-weeks", 12, "Weeks of throughput forecasts sample from")
+	flags.Int("forecast-trials", 10000, "Monte Carlo trials per forecast")
+
+	for _, key := range []string{
+		"server-host",
+		"server-port",
+		"snapshot-db",
+		"snapshot-interval",
+		"snapshot-epics",
+		"forecast-jql",
+		"forecast-weeks",
+		"forecast-trials",
+	} {
+		viper.BindPFlag(key, flags.Lookup(key))
+	}
+
+	rootCmd.AddCommand(serveCmd)
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"slices"
+
+	"github.com/Fuabioo/altalune/internal/tui"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/log"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var tuiCmd = &cobra.Command{
+	Use:   "tui [epic...]",
+	Short: "Browse epics in an interactive terminal dashboard",
+	Long: `Browse the saved epics, listed under epics in config.yaml, and the ones given
+as arguments. Each epic shows its progress, a list of its issues that can be
+filtered and sorted, and its dependency tree. Epics are fetched and cached the
+same way the web interface does.`,
+	Example: `  altalune tui
+  altalune tui ABC-123 ABC-456 --hops 1`,
+	PreRunE: requireJira,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		hops, err := hopsFlag(cmd)
+		if err != nil {
+			return err
+		}
+
+		// Logs would draw over the dashboard, so they go to a file or nowhere
+		log.SetOutput(io.Discard)
+		if path, _ := cmd.Flags().GetString("log-file"); path != "" {
+			file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+			if err != nil {
+				return err
+			}
+			defer file.Close()
+			log.SetOutput(file)
+		}
+
+		cfg := jiraConfig()
+		source, err := issueSource(cfg)
+		if err != nil {
+			return err
+		}
+
+		saved := viper.GetStringSlice("epics")
+		for _, epic := range args {
+			if !slices.Contains(saved, epic) {
+				saved = append(saved, epic)
+			}
+		}
+
+		program := tea.NewProgram(
+			tui.New(cmd.Context(), newLoader(source, cfg), saved, hops),
+			tea.WithAltScreen(),
+			tea.WithContext(cmd.Context()),
+		)
+		_, err = program.Run()
+		return err
+	},
+}
+
+func init() {
+	tuiCmd.Flags().Int("hops", 0, "Also follow links outside each epic up to this many links away")
+	tuiCmd.Flags().String("log-file", "", "File to write logs to while the dashboard is open (discarded when empty)")
+
+	rootCmd.AddCommand(tuiCmd)
+}
@@ -0,0 +1,140 @@
+package epics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// CacheStatus tells how a cached lookup was served.
+type CacheStatus string
+
+const (
+	CacheHit     CacheStatus = "HIT"     // Fresh entry
+	CacheStale   CacheStatus = "STALE"   // Stale entry, revalidating in the background
+	CacheMiss    CacheStatus = "MISS"    // No usable entry, fetched
+	CacheRefresh CacheStatus = "REFRESH" // Explicitly bypassed, fetched
+)
+
+type (
+	cacheEntry struct {
+		issues    []*model.Ticket
+		fetchedAt time.Time
+	}
+	// Result is the issues of a lookup, when they were fetched and how the
+	// lookup was served.
+	Result struct {
+		Issues    []*model.Ticket
+		FetchedAt time.Time
+		Status    CacheStatus
+	}
+	fetchFunc func(ctx context.Context) ([]*model.Ticket, error)
+
+	// epicCache holds the issues of recently fetched epics. Entries are
+	// fresh for ttl, then served stale for up to staleFor while a single
+	// background fetch revalidates them. Concurrent fetches of the same key
+	// are collapsed into one.
+	epicCache struct {
+		ttl      time.Duration
+		staleFor time.Duration
+		now      func() time.Time
+
+		mu      sync.Mutex
+		entries map[string]cacheEntry
+		flight  singleflight.Group
+	}
+)
+
+func newEpicCache(ttl time.Duration, staleFor time.Duration) *epicCache {
+	return &epicCache{
+		ttl:      ttl,
+		staleFor: staleFor,
+		now:      time.Now,
+		entries:  make(map[string]cacheEntry),
+	}
+}
+
+// get returns the issues cached under key, calling fetch when there's no
+// usable entry or refresh is set. fetch runs detached from ctx, so a client
+// going away doesn't fail the fetch for everyone else waiting on it.
+func (c *epicCache) get(ctx context.Context, key string, refresh bool, fetch fetchFunc) (Result, error) {
+	if !refresh {
+		c.mu.Lock()
+		entry, ok := c.entries[key]
+		c.mu.Unlock()
+
+		if ok {
+			age := c.now().Sub(entry.fetchedAt)
+			switch {
+			case age < c.ttl:
+				return Result{entry.issues, entry.fetchedAt, CacheHit}, nil
+			case age < c.ttl+c.staleFor:
+				c.revalidate(key, fetch)
+				return Result{entry.issues, entry.fetchedAt, CacheStale}, nil
+			}
+		}
+	}
+
+	status := CacheMiss
+	if refresh {
+		status = CacheRefresh
+		// Don't join a fetch that started before the refresh was asked for
+		c.flight.Forget(key)
+	}
+
+	ch := c.flight.DoChan(key, func() (any, error) {
+		return c.fill(context.WithoutCancel(ctx), key, fetch)
+	})
+
+	select {
+	case <-ctx.Done():
+		return Result{}, ctx.Err()
+	case res := <-ch:
+		if res.Err != nil {
+			return Result{}, res.Err
+		}
+		entry := res.Val.(cacheEntry)
+		return Result{entry.issues, entry.fetchedAt, status}, nil
+	}
+}
+
+func (c *epicCache) revalidate(key string, fetch fetchFunc) {
+	c.flight.DoChan(key, func() (any, error) {
+		entry, err := c.fill(context.Background(), key, fetch)
+		if err != nil {
+			log.Warn("Error revalidating cached epic", "key", key, "err", err)
+		}
+		return entry, err
+	})
+}
+
+func (c *epicCache) fill(ctx context.Context, key string, fetch fetchFunc) (cacheEntry, error) {
+	issues, err := fetch(ctx)
+	if err != nil {
+		return cacheEntry{}, err
+	}
+
+	entry := cacheEntry{
+		issues:    issues,
+		fetchedAt: c.now(),
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Drop entries too old to be served, so epics nobody looks at anymore
+	// don't pile up
+	for k, e := range c.entries {
+		if entry.fetchedAt.Sub(e.fetchedAt) >= c.ttl+c.staleFor {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = entry
+
+	return entry, nil
+}
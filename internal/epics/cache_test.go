@@ -0,0 +1,178 @@
+package epics
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// testCache is a cache with a clock the test moves, and a fetch that counts
+// its calls and returns a new generation of issues each time
+type testCache struct {
+	*epicCache
+
+	mu      sync.Mutex
+	clock   time.Time
+	fetches atomic.Int32
+}
+
+func newTestCache() *testCache {
+	c := &testCache{
+		epicCache: newEpicCache(time.Minute, time.Hour),
+		clock:     time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC),
+	}
+	c.epicCache.now = func() time.Time {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		return c.clock
+	}
+	return c
+}
+
+func (c *testCache) advance(d time.Duration) {
+	c.mu.Lock()
+	c.clock = c.clock.Add(d)
+	c.mu.Unlock()
+}
+
+func (c *testCache) fetch(ctx context.Context) ([]*model.Ticket, error) {
+	n := c.fetches.Add(1)
+	return []*model.Ticket{{Key: "ABC-" + strconv.Itoa(int(n))}}, nil
+}
+
+func (c *testCache) get(t *testing.T, refresh bool) Result {
+	t.Helper()
+
+	result, err := c.epicCache.get(context.Background(), "ABC-1", refresh, c.fetch)
+	if err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+	return result
+}
+
+// check asserts how the lookup was served, which generation of issues it
+// returned and how many fetches were made so far
+func (c *testCache) check(t *testing.T, result Result, status CacheStatus, generation string, fetches int32) {
+	t.Helper()
+
+	if result.Status != status {
+		t.Errorf("status = %s, want %s", result.Status, status)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Key != generation {
+		t.Errorf("issues = %v, want %s", result.Issues, generation)
+	}
+	if got := c.fetches.Load(); got != fetches {
+		t.Errorf("fetched %d times, want %d", got, fetches)
+	}
+}
+
+func TestEpicCacheHit(t *testing.T) {
+	c := newTestCache()
+
+	first := c.get(t, false)
+	c.check(t, first, CacheMiss, "ABC-1", 1)
+
+	c.advance(30 * time.Second)
+	hit := c.get(t, false)
+	c.check(t, hit, CacheHit, "ABC-1", 1)
+	if !hit.FetchedAt.Equal(first.FetchedAt) {
+		t.Errorf("FetchedAt = %s, want %s", hit.FetchedAt, first.FetchedAt)
+	}
+}
+
+func TestEpicCacheStale(t *testing.T) {
+	c := newTestCache()
+	first := c.get(t, false)
+
+	c.advance(30 * time.Minute)
+	stale := c.get(t, false)
+	if stale.Status != CacheStale || stale.Issues[0].Key != "ABC-1" || !stale.FetchedAt.Equal(first.FetchedAt) {
+		t.Errorf("get() = %s %v fetched at %s, want the stale ABC-1 from %s", stale.Status, stale.Issues, stale.FetchedAt, first.FetchedAt)
+	}
+
+	// The background fetch refills the entry, once
+	deadline := time.Now().Add(time.Second)
+	for {
+		c.epicCache.mu.Lock()
+		entry := c.entries["ABC-1"]
+		c.epicCache.mu.Unlock()
+		if entry.issues[0].Key == "ABC-2" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("the stale entry was never revalidated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	c.check(t, c.get(t, false), CacheHit, "ABC-2", 2)
+}
+
+func TestEpicCacheExpired(t *testing.T) {
+	c := newTestCache()
+	c.get(t, false)
+
+	// Past ttl+staleFor the entry is no longer served
+	c.advance(time.Minute + time.Hour)
+	c.check(t, c.get(t, false), CacheMiss, "ABC-2", 2)
+}
+
+func TestEpicCacheRefresh(t *testing.T) {
+	c := newTestCache()
+	c.get(t, false)
+
+	c.check(t, c.get(t, true), CacheRefresh, "ABC-2", 2)
+	c.check(t, c.get(t, false), CacheHit, "ABC-2", 2)
+}
+
+func TestEpicCacheCollapsesMisses(t *testing.T) {
+	c := newTestCache()
+
+	release := make(chan struct{})
+	fetch := func(ctx context.Context) ([]*model.Ticket, error) {
+		<-release
+		return c.fetch(ctx)
+	}
+
+	var wg sync.WaitGroup
+	results := make([]Result, 10)
+	errs := make([]error, len(results))
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = c.epicCache.get(context.Background(), "ABC-1", false, fetch)
+		}()
+	}
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	for i, result := range results {
+		if errs[i] != nil {
+			t.Fatalf("get() error = %v", errs[i])
+		}
+		c.check(t, result, CacheMiss, "ABC-1", 1)
+	}
+}
+
+func TestEpicCacheEvicts(t *testing.T) {
+	c := newTestCache()
+	c.get(t, false)
+
+	c.advance(time.Minute + time.Hour)
+	if _, err := c.epicCache.get(context.Background(), "ABC-2", false, c.fetch); err != nil {
+		t.Fatalf("get() error = %v", err)
+	}
+
+	c.epicCache.mu.Lock()
+	defer c.epicCache.mu.Unlock()
+	if _, ok := c.entries["ABC-1"]; ok || len(c.entries) != 1 {
+		t.Errorf("entries = %v, want only ABC-2 after ABC-1 expired", c.entries)
+	}
+}
@@ -0,0 +1,174 @@
+// Package epics loads the issues of epics from Jira through a cache shared by
+// everything that shows them, the HTTP server and the terminal interface.
+package epics
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+
+	"github.com/charmbracelet/log"
+)
+
+// Query is everything that determines which issues an epic fetch returns.
+// With JQL set the issues matching it are fetched instead of the epic's.
+type Query struct {
+	Key     string
+	JQL     string
+	Fields  []string
+	Expand  []string
+	Refresh bool
+}
+
+func (q Query) cacheKey() string {
+	fields := slices.Clone(q.Fields)
+	slices.Sort(fields)
+	return fmt.Sprintf("%s|%s|%s|%s", q.Key, q.JQL, strings.Join(fields, ","), strings.Join(q.Expand, ","))
+}
+
+// Config controls how epics are paged through and how long they are cached.
+// OnFetch, when set, is called with every epic freshly fetched from Jira
+// with the default fields and nothing expanded. The same epic fetched with
+// extra fields or its changelog isn't passed again, so that a page showing
+// it in several ways is seen once.
+type Config struct {
+	PageSize    uint
+	Concurrency int
+	Timeout     time.Duration
+	CacheTTL    time.Duration
+	CacheStale  time.Duration
+	OnFetch     func(ctx context.Context, epic string, issues []*model.Ticket)
+}
+
+// Loader fetches epics from a source, serving them from the cache while
+// they are fresh, see epicCache.
+type Loader struct {
+	config Config
+	source jira.IssueSource
+	points *jira.StoryPointResolver
+	cache  *epicCache
+}
+
+func NewLoader(source jira.IssueSource, points *jira.StoryPointResolver, cfg Config) *Loader {
+	return &Loader{
+		config: cfg,
+		source: source,
+		points: points,
+		cache:  newEpicCache(cfg.CacheTTL, cfg.CacheStale),
+	}
+}
+
+// Points returns the story point fields to read, see
+// jira.StoryPointResolver.
+func (l *Loader) Points(ctx context.Context) jira.StoryPointFields {
+	return l.points.Fields(ctx)
+}
+
+// Epic returns the epic's issues from the cache, fetching them from Jira
+// when needed.
+func (l *Loader) Epic(ctx context.Context, query Query) (Result, error) {
+	return l.cache.get(ctx, query.cacheKey(), query.Refresh, func(ctx context.Context) ([]*model.Ticket, error) {
+		ctx, cancel := context.WithTimeout(ctx, l.config.Timeout)
+		defer cancel()
+
+		issues, err := jira.FetchEpicIssues(ctx, l.source, jira.ListEpicRequest{
+			EpicID:   query.Key,
+			JQL:      query.JQL,
+			PageSize: l.config.PageSize,
+			Fields:   query.Fields,
+			Expand:   query.Expand,
+		}, l.config.Concurrency)
+		if err != nil {
+			return nil, err
+		}
+
+		if slices.Contains(query.Expand, jira.ExpandChangelog) {
+			if err := jira.CompleteChangelogs(ctx, l.source, issues, l.config.Concurrency); err != nil {
+				return nil, err
+			}
+		}
+
+		log.Info("Epic issues listed",
+			"epic", query.Key,
+			"total", len(issues),
+		)
+
+		if l.config.OnFetch != nil && l.isDefault(ctx, query) {
+			l.config.OnFetch(ctx, query.Key, issues)
+		}
+
+		return issues, nil
+	})
+}
+
+// isDefault tells whether the query fetches the epic itself with the
+// default fields and nothing expanded.
+func (l *Loader) isDefault(ctx context.Context, query Query) bool {
+	if query.JQL != "" || len(query.Expand) > 0 {
+		return false
+	}
+
+	fields := slices.Clone(query.Fields)
+	defaults := jira.DefaultFields(l.points.Fields(ctx))
+	slices.Sort(fields)
+	slices.Sort(defaults)
+	return slices.Equal(fields, defaults)
+}
+
+// Linked returns the issues outside the epic linked from the epic's issues
+// up to hops links away. They're cached alongside the epic, keyed by when it
+// was fetched, so that refilling the epic also refetches its linked issues.
+func (l *Loader) Linked(ctx context.Context, query Query, epic Result, hops int) (Result, error) {
+	key := fmt.Sprintf("linked|%d|%d|%s", hops, epic.FetchedAt.UnixNano(), query.cacheKey())
+	issues := epic.Issues
+
+	return l.cache.get(ctx, key, query.Refresh, func(ctx context.Context) ([]*model.Ticket, error) {
+		ctx, cancel := context.WithTimeout(ctx, l.config.Timeout)
+		defer cancel()
+
+		linked, err := jira.ExpandLinkedIssues(ctx, l.source, issues, hops, jira.ListEpicRequest{
+			PageSize: l.config.PageSize,
+			Fields:   query.Fields,
+		}, l.config.Concurrency)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Info("Linked issues expanded",
+			"epic", query.Key,
+			"hops", hops,
+			"total", len(linked),
+		)
+
+		return linked, nil
+	})
+}
+
+// MaxHops caps how many links away the web interface and the CLI follow
+// issues outside an epic.
+const MaxHops = 3
+
+// EpicWithLinks returns the epic's issues and, with hops, the issues
+// outside the epic they link to, see Linked.
+func (l *Loader) EpicWithLinks(ctx context.Context, query Query, hops int) (Result, []*model.Ticket, error) {
+	result, err := l.Epic(ctx, query)
+	if err != nil {
+		return Result{}, nil, err
+	}
+
+	if hops == 0 {
+		return result, nil, nil
+	}
+
+	linked, err := l.Linked(ctx, query, result, hops)
+	if err != nil {
+		return Result{}, nil, err
+	}
+
+	return result, linked.Issues, nil
+}
@@ -0,0 +1,98 @@
+package epics
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+func TestLoaderOnFetch(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := jira.LoadMemorySource(os.DirFS("../jira/testdata/ABC-1"))
+	if err != nil {
+		t.Fatalf("LoadMemorySource() error = %v", err)
+	}
+
+	var fetched []string
+	loader := NewLoader(source, jira.NewStoryPointResolver(source, jira.Config{}), Config{
+		PageSize:    50,
+		Concurrency: 2,
+		Timeout:     time.Second,
+		CacheTTL:    time.Minute,
+		CacheStale:  time.Minute,
+		OnFetch: func(ctx context.Context, epic string, issues []*model.Ticket) {
+			fetched = append(fetched, epic)
+		},
+	})
+
+	defaults := jira.DefaultFields(loader.Points(ctx))
+	for _, query := range []Query{
+		{Key: "ABC-1", Fields: defaults},
+		{Key: "ABC-1", Fields: jira.MergeFields(defaults, []string{"labels"})},
+		{Key: "ABC-1", Fields: defaults, Expand: []string{jira.ExpandChangelog}},
+		{Key: "ABC-1", Fields: defaults, Refresh: true},
+	} {
+		if _, err := loader.Epic(ctx, query); err != nil {
+			t.Fatalf("Epic(%+v) error = %v", query, err)
+		}
+	}
+
+	// The default fetch, then its refresh
+	if len(fetched) != 2 {
+		t.Errorf("OnFetch was called for %v, want the two default fetches", fetched)
+	}
+}
+
+func TestLoaderLinkedFollowsEpic(t *testing.T) {
+	ctx := context.Background()
+
+	source, err := jira.LoadMemorySource(os.DirFS("../jira/testdata/ABC-1"))
+	if err != nil {
+		t.Fatalf("LoadMemorySource() error = %v", err)
+	}
+
+	loader := NewLoader(source, jira.NewStoryPointResolver(source, jira.Config{}), Config{
+		PageSize:    50,
+		Concurrency: 2,
+		Timeout:     time.Second,
+		CacheTTL:    time.Minute,
+		CacheStale:  time.Minute,
+	})
+	now := time.Date(2026, time.October, 1, 12, 0, 0, 0, time.UTC)
+	loader.cache.now = func() time.Time { return now }
+
+	query := Query{Key: "ABC-1", Fields: jira.DefaultFields(loader.Points(ctx))}
+	linked := func(epic Result, want CacheStatus) {
+		t.Helper()
+		result, err := loader.Linked(ctx, query, epic, 1)
+		if err != nil {
+			t.Fatalf("Linked() error = %v", err)
+		}
+		if result.Status != want {
+			t.Errorf("Linked() status = %s, want %s", result.Status, want)
+		}
+	}
+
+	epic, err := loader.Epic(ctx, query)
+	if err != nil {
+		t.Fatalf("Epic() error = %v", err)
+	}
+	linked(epic, CacheMiss)
+	linked(epic, CacheHit)
+
+	// Refilling the epic refetches its linked issues, even though the
+	// linked entry is still fresh
+	now = now.Add(time.Second)
+	refresh := query
+	refresh.Refresh = true
+	if epic, err = loader.Epic(ctx, refresh); err != nil {
+		t.Fatalf("Epic() error = %v", err)
+	}
+	linked(epic, CacheMiss)
+	linked(epic, CacheHit)
+}
@@ -0,0 +1,225 @@
+package jira
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+type BurnDay struct {
+	Date            string  `json:"date"`            // Day, YYYY-MM-DD
+	Scope           int     `json:"scope"`           // Issues in the epic by the end of the day
+	Done            int     `json:"done"`            // Issues done by the end of the day
+	Remaining       int     `json:"remaining"`       // Scope - Done
+	Added           int     `json:"added"`           // Issues added to the epic during the day
+	ScopePoints     float64 `json:"scopePoints"`     // Story points in the epic by the end of the day
+	DonePoints      float64 `json:"donePoints"`      // Story points done by the end of the day
+	RemainingPoints float64 `json:"remainingPoints"` // ScopePoints - DonePoints
+	AddedPoints     float64 `json:"addedPoints"`     // Story points added to the epic during the day
+}
+
+// BurnChart is a daily series that serves both burndown (Remaining) and
+// burnup (Scope against Done) charts, by issue count and by story points.
+type BurnChart struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Days []BurnDay `json:"days"`
+}
+
+// parentFields are the changelog fields that record an issue being moved
+// in or out of an epic, depending on the Jira flavour and project type.
+var parentFields = []string{"parent", "epic link", "issueparentassociation"}
+
+// membership is a period the issue spent in the epic. left is zero while
+// it's still in it.
+type membership struct {
+	joined time.Time
+	left   time.Time
+}
+
+// includes tells whether the issue was in the epic just before t
+func (m membership) includes(t time.Time) bool {
+	return m.joined.Before(t) && (m.left.IsZero() || !m.left.Before(t))
+}
+
+// epicMembership replays the issue's moves in and out of the epic, oldest
+// first. An issue whose changelog never mentions the epic has been in it
+// since it was created, and one whose changelog ends outside of it is in it
+// now anyway, since it was found among its issues.
+func epicMembership(issue *model.Ticket, epicKey string) []membership {
+	created := issue.Fields.Created.Time
+
+	type move struct {
+		at          time.Time
+		into, outOf bool
+	}
+	var moves []move
+	mentioned := false
+	if issue.Changelog != nil {
+		for _, history := range issue.Changelog.Histories {
+			for _, item := range history.Items {
+				if !isParentField(item.Field) {
+					continue
+				}
+				m := move{at: history.Created.Time, into: item.ToString == epicKey, outOf: item.FromString == epicKey}
+				mentioned = mentioned || m.into || m.outOf
+				moves = append(moves, m)
+			}
+		}
+	}
+	if !mentioned {
+		return []membership{{joined: created}}
+	}
+	slices.SortStableFunc(moves, func(a, b move) int {
+		return a.at.Compare(b.at)
+	})
+
+	var periods []membership
+	in := moves[0].outOf
+	if in {
+		periods = append(periods, membership{joined: created})
+	}
+	for _, m := range moves {
+		switch {
+		case m.into && !in:
+			periods = append(periods, membership{joined: m.at})
+			in = true
+		case !m.into && in:
+			periods[len(periods)-1].left = m.at
+			in = false
+		}
+	}
+	if !in {
+		periods = append(periods, membership{joined: moves[len(moves)-1].at})
+	}
+
+	return periods
+}
+
+// AddedAt returns when the issue last joined the epic: the last time it was
+// moved into it according to its changelog, or its creation otherwise.
+func AddedAt(issue *model.Ticket, epicKey string) time.Time {
+	periods := epicMembership(issue, epicKey)
+	return periods[len(periods)-1].joined
+}
+
+func isParentField(field string) bool {
+	for _, parentField := range parentFields {
+		if strings.EqualFold(field, parentField) {
+			return true
+		}
+	}
+	return false
+}
+
+// CategoryAt reconstructs the status category the issue was in at t by
+// replaying its status transitions.
+func CategoryAt(issue *model.Ticket, transitions []model.StatusTransition, categories StatusCategories, t time.Time) string {
+	if len(transitions) == 0 {
+		return issue.Fields.StatusCategory.Key
+	}
+
+	first := transitions[0]
+	category := categories.Of(first.FromStatusID, first.FromStatus)
+	for _, transition := range transitions {
+		if transition.At.After(t) {
+			break
+		}
+		category = categories.Of(transition.ToStatusID, transition.ToStatus)
+	}
+
+	return category
+}
+
+// BuildBurnChart rebuilds the epic's daily scope and progress between from
+// and to, in UTC days, from the changelogs of its issues, including issues
+// moving in and out of the epic. Story points are the current estimates,
+// re-estimations are not replayed.
+func BuildBurnChart(issues []*model.Ticket, epicKey string, categories StatusCategories, points StoryPointFields, from time.Time, to time.Time) BurnChart {
+	from = StartOfDay(from)
+	to = StartOfDay(to)
+
+	chart := BurnChart{
+		From: from.Format(time.DateOnly),
+		To:   to.Format(time.DateOnly),
+		Days: []BurnDay{},
+	}
+
+	type issueHistory struct {
+		issue       *model.Ticket
+		membership  []membership
+		transitions []model.StatusTransition
+		points      float64
+	}
+
+	histories := make([]issueHistory, 0, len(issues))
+	for _, issue := range issues {
+		histories = append(histories, issueHistory{
+			issue:       issue,
+			membership:  epicMembership(issue, epicKey),
+			transitions: issue.StatusTransitions(),
+			points:      points.Of(issue),
+		})
+	}
+
+	for day := from; !day.After(to); day = day.AddDate(0, 0, 1) {
+		end := day.AddDate(0, 0, 1)
+		burn := BurnDay{
+			Date: day.Format(time.DateOnly),
+		}
+
+		for _, history := range histories {
+			// In the epic by the end of the day, and whether it joined during it
+			var in, added bool
+			for _, period := range history.membership {
+				if period.includes(end) {
+					in = true
+					added = !period.joined.Before(day)
+				}
+			}
+			if !in {
+				continue
+			}
+
+			burn.Scope++
+			burn.ScopePoints += history.points
+
+			if added {
+				burn.Added++
+				burn.AddedPoints += history.points
+			}
+
+			if CategoryAt(history.issue, history.transitions, categories, end) == "done" {
+				burn.Done++
+				burn.DonePoints += history.points
+			}
+		}
+
+		burn.Remaining = burn.Scope - burn.Done
+		burn.RemainingPoints = burn.ScopePoints - burn.DonePoints
+		chart.Days = append(chart.Days, burn)
+	}
+
+	return chart
+}
+
+// EarliestAdded returns when the first issue joined the epic, or the zero
+// time when there are no issues.
+func EarliestAdded(issues []*model.Ticket, epicKey string) time.Time {
+	var earliest time.Time
+	for _, issue := range issues {
+		added := epicMembership(issue, epicKey)[0].joined
+		if !added.IsZero() && (earliest.IsZero() || added.Before(earliest)) {
+			earliest = added
+		}
+	}
+	return earliest
+}
+
+// StartOfDay truncates t to midnight UTC
+func StartOfDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
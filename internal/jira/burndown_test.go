@@ -0,0 +1,147 @@
+package jira
+
+import (
+	"context"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// loadBurnFixtures loads the BRN-1 epic, whose issues move in and out of it
+// and through their statuses in early October 2026, and its statuses
+func loadBurnFixtures(t *testing.T) ([]*model.Ticket, StatusCategories) {
+	t.Helper()
+
+	source, err := LoadMemorySource(os.DirFS("testdata/BRN-1"))
+	if err != nil {
+		t.Fatalf("LoadMemorySource() error = %v", err)
+	}
+
+	ctx := context.Background()
+	result, err := source.ListEpicIssues(ctx, ListEpicRequest{EpicID: "BRN-1"})
+	if err != nil {
+		t.Fatalf("ListEpicIssues() error = %v", err)
+	}
+	statuses, err := source.ListStatuses(ctx)
+	if err != nil {
+		t.Fatalf("ListStatuses() error = %v", err)
+	}
+
+	return result.Issues, NewStatusCategories(statuses)
+}
+
+func findIssue(t *testing.T, issues []*model.Ticket, key string) *model.Ticket {
+	t.Helper()
+
+	for _, issue := range issues {
+		if issue.Key == key {
+			return issue
+		}
+	}
+	t.Fatalf("no issue %s in the fixtures", key)
+	return nil
+}
+
+func date(day int, hour int) time.Time {
+	return time.Date(2026, 10, day, hour, 0, 0, 0, time.UTC)
+}
+
+func TestBuildBurnChart(t *testing.T) {
+	issues, categories := loadBurnFixtures(t)
+	points := StoryPointFields{"customfield_10016"}
+
+	chart := BuildBurnChart(issues, "BRN-1", categories, points, date(1, 15), date(6, 8))
+
+	// BRN-2 is done on the 2nd, BRN-7 on the 1st. BRN-3 joins on the 3rd,
+	// BRN-6 is created on the 4th and BRN-5 leaves on the 2nd to come back
+	// on the 5th. BRN-4 is done on the 2nd and reopened on the 4th, BRN-8 is
+	// reopened on the 3rd and done again on the 5th.
+	day := func(date string, scope, done, added int, scopePoints, donePoints, addedPoints float64) BurnDay {
+		return BurnDay{
+			Date:            date,
+			Scope:           scope,
+			Done:            done,
+			Remaining:       scope - done,
+			Added:           added,
+			ScopePoints:     scopePoints,
+			DonePoints:      donePoints,
+			RemainingPoints: scopePoints - donePoints,
+			AddedPoints:     addedPoints,
+		}
+	}
+	want := BurnChart{
+		From: "2026-10-01",
+		To:   "2026-10-06",
+		Days: []BurnDay{
+			day("2026-10-01", 5, 2, 0, 6, 0, 0),
+			day("2026-10-02", 4, 4, 0, 5, 5, 0),
+			day("2026-10-03", 5, 3, 1, 10, 5, 5),
+			day("2026-10-04", 6, 2, 1, 14, 3, 4),
+			day("2026-10-05", 7, 3, 1, 15, 3, 1),
+			day("2026-10-06", 7, 3, 0, 15, 3, 0),
+		},
+	}
+
+	if !reflect.DeepEqual(chart, want) {
+		t.Errorf("BuildBurnChart() =\n%+v\nwant\n%+v", chart, want)
+	}
+
+	if before := BuildBurnChart(issues, "BRN-1", categories, points, date(1, 0).AddDate(0, 0, -20), date(1, 0).AddDate(0, 0, -20)); before.Days[0].Scope != 0 {
+		t.Errorf("scope before any issue was created = %d, want 0", before.Days[0].Scope)
+	}
+}
+
+func TestAddedAt(t *testing.T) {
+	issues, _ := loadBurnFixtures(t)
+
+	tests := []struct {
+		key  string
+		want time.Time
+	}{
+		{"BRN-2", time.Date(2026, 9, 28, 10, 0, 0, 0, time.UTC)},
+		{"BRN-3", date(3, 12)},
+		{"BRN-5", date(5, 10)},
+		{"BRN-6", date(4, 9)},
+	}
+	for _, tt := range tests {
+		if got := AddedAt(findIssue(t, issues, tt.key), "BRN-1"); !got.Equal(tt.want) {
+			t.Errorf("AddedAt(%s) = %s, want %s", tt.key, got, tt.want)
+		}
+	}
+
+	// BRN-8 was created in the epic on September 20th
+	if got, want := EarliestAdded(issues, "BRN-1"), time.Date(2026, 9, 20, 10, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("EarliestAdded() = %s, want %s", got, want)
+	}
+}
+
+func TestCategoryAt(t *testing.T) {
+	issues, categories := loadBurnFixtures(t)
+	reopened := findIssue(t, issues, "BRN-4")
+	transitions := reopened.StatusTransitions()
+
+	tests := []struct {
+		at   time.Time
+		want string
+	}{
+		{time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC), "new"},
+		{date(2, 9), "new"},
+		{date(2, 10), "done"},
+		{date(3, 0), "done"},
+		{date(4, 11), "new"},
+	}
+	for _, tt := range tests {
+		if got := CategoryAt(reopened, transitions, categories, tt.at); got != tt.want {
+			t.Errorf("CategoryAt(%s) = %q, want %q", tt.at, got, tt.want)
+		}
+	}
+
+	// Without a changelog the current status is all there is
+	open := findIssue(t, issues, "BRN-6")
+	if got := CategoryAt(open, open.StatusTransitions(), categories, date(1, 0)); got != "new" {
+		t.Errorf("CategoryAt() without transitions = %q, want new", got)
+	}
+}
@@ -0,0 +1,92 @@
+package jira
+
+import (
+	"context"
+	"strings"
+
+	"github.com/Fuabioo/altalune/internal/model"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// ExpandChangelog asks searches to embed each issue's changelog.
+const ExpandChangelog = "changelog"
+
+// CompleteChangelogs fetches the full changelog of the issues whose embedded
+// changelog was truncated by the search, using up to concurrency workers.
+func CompleteChangelogs(ctx context.Context, source IssueSource, issues []*model.Ticket, concurrency int) error {
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(max(concurrency, 1))
+
+	for _, issue := range issues {
+		if issue.Changelog.IsComplete() {
+			continue
+		}
+
+		group.Go(func() error {
+			histories, err := source.ListIssueChangelog(ctx, issue.Key)
+			if err != nil {
+				return err
+			}
+
+			issue.Changelog = &model.Changelog{
+				MaxResults: len(histories),
+				Total:      len(histories),
+				Histories:  histories,
+			}
+			return nil
+		})
+	}
+
+	return group.Wait()
+}
+
+// StatusCategories maps statuses, by ID and by name, to the key of their
+// status category (new, indeterminate or done). Changelogs only name the
+// statuses an issue went through, so this is what tells which of them
+// meant the work was done.
+type StatusCategories map[string]string
+
+func NewStatusCategories(statuses []model.Status) StatusCategories {
+	categories := make(StatusCategories, len(statuses)*2)
+	for _, status := range statuses {
+		if status.StatusCategory == nil {
+			continue
+		}
+		categories.add(status.ID, status.Name, status.StatusCategory.Key)
+	}
+	return categories
+}
+
+// Learn records the current status of each issue, which covers statuses
+// missing from the list Jira returned.
+func (c StatusCategories) Learn(issues []*model.Ticket) StatusCategories {
+	for _, issue := range issues {
+		status := issue.Fields.Status
+		if _, known := c[status.ID]; known && status.ID != "" {
+			continue
+		}
+		c.add(status.ID, status.Name, issue.Fields.StatusCategory.Key)
+	}
+	return c
+}
+
+// Of returns the category of a status, or an empty string if it's unknown.
+func (c StatusCategories) Of(statusID string, statusName string) string {
+	if category, ok := c[statusID]; ok && statusID != "" {
+		return category
+	}
+	return c[strings.ToLower(statusName)]
+}
+
+func (c StatusCategories) add(statusID string, statusName string, category string) {
+	if category == "" {
+		return
+	}
+	if statusID != "" {
+		c[statusID] = category
+	}
+	if statusName != "" {
+		c[strings.ToLower(statusName)] = category
+	}
+}
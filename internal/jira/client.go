@@ -3,7 +3,10 @@ package jira
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"regexp"
+	"strings"
 
 	"github.com/Fuabioo/altalune/internal/model"
 	cliutils "github.com/Fuabioo/altalune/pkg/cliutls"
@@ -12,38 +15,139 @@ import (
 	"resty.dev/v3"
 )
 
+// SearchAPI selects which Jira search endpoint is used to list issues.
+type SearchAPI string
+
+const (
+	// SearchAPIJQL is the token-paginated /search/jql endpoint.
+	SearchAPIJQL SearchAPI = "jql"
+	// SearchAPILegacy is the offset-paginated /search endpoint that
+	// Atlassian is retiring.
+	SearchAPILegacy SearchAPI = "legacy"
+)
+
+// AuthMode selects how the client authenticates against Jira.
+type AuthMode string
+
+const (
+	// AuthBasic sends email and API token, as Jira Cloud expects.
+	AuthBasic AuthMode = "basic"
+	// AuthBearer sends a personal access token, as Jira Data Center and
+	// Server expect.
+	AuthBearer AuthMode = "bearer"
+)
+
 type Client struct {
-	client *resty.Client
+	client     *resty.Client
+	apiVersion string
+	searchAPI  SearchAPI
+	retries    *retryCounters
 }
 
 type Config struct {
-	Email      string
-	Token      string
-	Workspace  string
-	SuperDebug bool
+	// Host is the domain Jira is served from, e.g. company.atlassian.net
+	Host  string
+	Email string
+	Token string
+	Auth  AuthMode
+	// APIVersion is the REST API version, "3" for Jira Cloud and "2" for
+	// Jira Data Center and Server.
+	APIVersion string
+	// BasePath overrides the /rest/api/<version> default, e.g. when Jira is
+	// served under a context path such as /jira/rest/api/2.
+	BasePath string
+	// BaseURL overrides https://<host><base path> entirely, e.g. to
+	// point the client at an httptest server.
+	BaseURL   string
+	SearchAPI SearchAPI
+	// StoryPointField pins the story point field by ID or name instead of
+	// discovering it through /field.
+	StoryPointField string
+	// StoryPointFields are the candidates used when discovery fails.
+	StoryPointFields StoryPointFields
+	Retry            RetryPolicy
+	SuperDebug       bool
+}
+
+// WithDefaults fills in the settings that depend on the API version. API v2
+// has no /search/jql endpoint, so it falls back to the legacy search.
+func (cfg Config) WithDefaults() Config {
+	if cfg.Auth == "" {
+		cfg.Auth = AuthBasic
+	}
+	if cfg.APIVersion == "" {
+		cfg.APIVersion = "3"
+	}
+	if cfg.BasePath == "" {
+		cfg.BasePath = fmt.Sprintf("/rest/api/%s", cfg.APIVersion)
+	}
+	if cfg.BaseURL == "" {
+		cfg.BaseURL = fmt.Sprintf("https://%s%s", cfg.Host, cfg.BasePath)
+	}
+	if cfg.SearchAPI == "" {
+		cfg.SearchAPI = SearchAPIJQL
+		if cfg.APIVersion == "2" {
+			cfg.SearchAPI = SearchAPILegacy
+		}
+	}
+	if len(cfg.StoryPointFields) == 0 {
+		cfg.StoryPointFields = DefaultStoryPointFields(cfg.APIVersion)
+	}
+	if cfg.Retry.MaxAttempts < 1 {
+		cfg.Retry = DefaultRetryPolicy()
+	}
+	return cfg
 }
 
 func NewClient(cfg Config) *Client {
+	cfg = cfg.WithDefaults()
 
 	maskedToken := cliutils.MaskToken(cfg.Token)
 	log.Debug("Initializing Jira client",
-		"workspace", cfg.Workspace,
+		"host", cfg.Host,
 		"email", cfg.Email,
 		"token", maskedToken,
+		"auth", cfg.Auth,
+		"baseURL", cfg.BaseURL,
+		"searchAPI", cfg.SearchAPI,
+		"maxAttempts", cfg.Retry.MaxAttempts,
 		"superDebug", cfg.SuperDebug,
 	)
 
+	retries := &retryCounters{}
+
+	client := resty.New().
+		SetDebug(cfg.SuperDebug).
+		SetLogger(log.Default()).
+		SetHeader("Content-Type", "application/json").
+		SetHeader("Accept", "application/json").
+		SetBaseURL(cfg.BaseURL).
+		SetRetryCount(cfg.Retry.MaxAttempts - 1).
+		SetRetryWaitTime(cfg.Retry.InitialBackoff).
+		SetRetryMaxWaitTime(cfg.Retry.MaxBackoff).
+		AddRetryHooks(retries.hook)
+
+	switch cfg.Auth {
+	case AuthBearer:
+		client.SetAuthToken(cfg.Token)
+	default:
+		client.SetBasicAuth(cfg.Email, cfg.Token)
+	}
+
 	return &Client{
-		client: resty.New().
-			SetDebug(cfg.SuperDebug).
-			SetLogger(log.Default()).
-			SetHeader("Content-Type", "application/json").
-			SetHeader("Accept", "application/json").
-			SetBasicAuth(cfg.Email, cfg.Token).
-			SetBaseURL(fmt.Sprintf("https://%s/rest/api/3", cfg.Workspace)),
+		apiVersion: cfg.APIVersion,
+		searchAPI:  cfg.SearchAPI,
+		retries:    retries,
+		client:     client,
 	}
 }
 
+// RetryStats reports how many requests have been retried since the client
+// was created.
+func (c *Client) RetryStats() RetryStats {
+	return c.retries.snapshot()
+}
+
 func (c *Client) Ping(ctx context.Context) error {
 	resp, err := c.client.R().
 		SetContext(ctx).
@@ -52,31 +156,178 @@ func (c *Client) Ping(ctx context.Context) error {
 		return fmt.Errorf("error making request: %w", err)
 	}
 
-	if resp.StatusCode() != 200 {
-		return fmt.Errorf("unexpected status code: %d", resp.StatusCode())
+	return checkResponse(resp)
+}
+
+// Myself returns the user the credentials belong to.
+func (c *Client) Myself(ctx context.Context) (model.User, error) {
+	resp, err := c.client.R().
+		SetContext(ctx).
+		Get("/myself")
+	if err != nil {
+		return model.User{}, fmt.Errorf("error making request: %w", err)
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return model.User{}, err
+	}
+
+	var user model.User
+	if err := json.Unmarshal(resp.Bytes(), &user); err != nil {
+		return model.User{}, fmt.Errorf("error unmarshalling response: %w", err)
+	}
+
+	return user, nil
+}
+
+// HasPermission tells whether the user holds a project permission, such as
+// BROWSE_PROJECTS, on the project.
+func (c *Client) HasPermission(ctx context.Context, projectKey string, permission string) (bool, error) {
+	resp, err := c.client.R().
+		SetContext(ctx).
+		SetQueryParam("projectKey", projectKey).
+		SetQueryParam("permissions", permission).
+		Get("/mypermissions")
+	if err != nil {
+		return false, fmt.Errorf("error making request: %w", err)
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return false, err
+	}
+
+	var body struct {
+		Permissions map[string]struct {
+			HavePermission bool `json:"havePermission"`
+		} `json:"permissions"`
+	}
+	if err := json.Unmarshal(resp.Bytes(), &body); err != nil {
+		return false, fmt.Errorf("error unmarshalling response: %w", err)
+	}
+
+	return body.Permissions[permission].HavePermission, nil
+}
+
+// ListFields lists the metadata of every system and custom field.
+func (c *Client) ListFields(ctx context.Context) ([]model.FieldMeta, error) {
+	resp, err := c.client.R().
+		SetContext(ctx).
+		Get("/field")
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
 	}
 
-	return nil
+	var fields []model.FieldMeta
+	if err := json.Unmarshal(resp.Bytes(), &fields); err != nil {
+		return nil, fmt.Errorf("error unmarshalling response: %w", err)
+	}
+
+	return fields, nil
 }
 
 type ListEpicRequest struct {
 	EpicID   string
-	StartAt  uint
 	PageSize uint
+
+	// Keys restricts the search to the given issues instead of the epic's
+	// children.
+	Keys []string
+	// JQL replaces the epic's children with an arbitrary query.
+	JQL string
+
+	// StartAt is the offset used by the legacy search endpoint.
+	StartAt uint
+	// NextPageToken is the cursor used by the /search/jql endpoint.
+	NextPageToken string
+
+	// Fields is the projection sent to Jira, see DefaultFields. Empty means
+	// every field.
+	Fields []string
+
+	// Expand lists the extra entities to embed in each issue, such as
+	// "changelog".
+	Expand []string
 }
 
+// ErrInvalidKey is returned for an issue key that isn't a project key
+// followed by a number, which could otherwise change the JQL it's part of.
+var ErrInvalidKey = errors.New("invalid issue key")
+
+var issueKeyPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_]*-[0-9]+$`)
+
+// ValidIssueKey tells whether key looks like an issue key, such as ABC-123
+func ValidIssueKey(key string) bool {
+	return issueKeyPattern.MatchString(key)
+}
+
+// Query returns the JQL the request translates to. The keys and the epic
+// are checked first, as they are written into it as they are.
+func (r ListEpicRequest) Query() (string, error) {
+	if len(r.Keys) > 0 {
+		for _, key := range r.Keys {
+			if !ValidIssueKey(key) {
+				return "", fmt.Errorf("%w %q", ErrInvalidKey, key)
+			}
+		}
+		return fmt.Sprintf("key in (%s)", strings.Join(r.Keys, ",")), nil
+	}
+	if r.JQL != "" {
+		return r.JQL, nil
+	}
+	if !ValidIssueKey(r.EpicID) {
+		return "", fmt.Errorf("%w %q", ErrInvalidKey, r.EpicID)
+	}
+	return fmt.Sprintf("parent = %s", r.EpicID), nil
+}
+
+// ListEpicIssues fetches a single page of the issues whose parent is the
+// given epic. Use EpicPages to walk every page.
 func (c *Client) ListEpicIssues(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error) {
+	query, err := req.Query()
+	if err != nil {
+		return nil, err
+	}
 
-	resp, err := c.client.R().
+	request := c.client.R().
 		SetContext(ctx).
-		SetQueryParam("jql", fmt.Sprintf("parent = %s", req.EpicID)).
-		SetQueryParam("startAt", fmt.Sprintf("%d", req.StartAt)).
-		SetQueryParam("maxResults", fmt.Sprintf("%d", req.PageSize)).
-		Get("/search")
+		SetQueryParam("jql", query).
+		SetQueryParam("maxResults", fmt.Sprintf("%d", req.PageSize))
+
+	// /search/jql only returns issue IDs unless fields are requested
+	fields := "*all"
+	if len(req.Fields) > 0 {
+		fields = strings.Join(req.Fields, ",")
+	}
+	request.SetQueryParam("fields", fields)
+
+	if len(req.Expand) > 0 {
+		request.SetQueryParam("expand", strings.Join(req.Expand, ","))
+	}
+
+	path := "/search/jql"
+	switch c.searchAPI {
+	case SearchAPILegacy:
+		path = "/search"
+		request.SetQueryParam("startAt", fmt.Sprintf("%d", req.StartAt))
+	default:
+		if req.NextPageToken != "" {
+			request.SetQueryParam("nextPageToken", req.NextPageToken)
+		}
+	}
+
+	resp, err := request.Get(path)
 	if err != nil {
 		return nil, fmt.Errorf("error making request: %w", err)
 	}
 
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
 	var result model.SearchResult
 	if err := json.Unmarshal(resp.Bytes(), &result); err != nil {
 		return nil, fmt.Errorf("error unmarshalling response: %w", err)
@@ -84,3 +335,83 @@ func (c *Client) ListEpicIssues(ctx context.Context, req ListEpicRequest) (*mode
 
 	return &result, nil
 }
+
+// ListStatuses lists every status with its status category.
+func (c *Client) ListStatuses(ctx context.Context) ([]model.Status, error) {
+	resp, err := c.client.R().
+		SetContext(ctx).
+		Get("/status")
+	if err != nil {
+		return nil, fmt.Errorf("error making request: %w", err)
+	}
+
+	if err := checkResponse(resp); err != nil {
+		return nil, err
+	}
+
+	var statuses []model.Status
+	if err := json.Unmarshal(resp.Bytes(), &statuses); err != nil {
+		return nil, fmt.Errorf("error unmarshalling response: %w", err)
+	}
+
+	return statuses, nil
+}
+
+// ListIssueChangelog fetches the complete changelog of an issue, oldest
+// first. API v2 has no paginated changelog endpoint, but embeds the complete
+// changelog when the issue is expanded.
+func (c *Client) ListIssueChangelog(ctx context.Context, key string) ([]model.ChangelogHistory, error) {
+	if c.apiVersion == "2" {
+		resp, err := c.client.R().
+			SetContext(ctx).
+			SetPathParam("key", key).
+			SetQueryParam("fields", "status").
+			SetQueryParam("expand", "changelog").
+			Get("/issue/{key}")
+		if err != nil {
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+
+		if err := checkResponse(resp); err != nil {
+			return nil, err
+		}
+
+		var ticket model.Ticket
+		if err := json.Unmarshal(resp.Bytes(), &ticket); err != nil {
+			return nil, fmt.Errorf("error unmarshalling response: %w", err)
+		}
+
+		if ticket.Changelog == nil {
+			return nil, nil
+		}
+		return ticket.Changelog.Histories, nil
+	}
+
+	var histories []model.ChangelogHistory
+	for {
+		resp, err := c.client.R().
+			SetContext(ctx).
+			SetPathParam("key", key).
+			SetQueryParam("startAt", fmt.Sprintf("%d", len(histories))).
+			SetQueryParam("maxResults", "100").
+			Get("/issue/{key}/changelog")
+		if err != nil {
+			return nil, fmt.Errorf("error making request: %w", err)
+		}
+
+		if err := checkResponse(resp); err != nil {
+			return nil, err
+		}
+
+		var page model.ChangelogPage
+		if err := json.Unmarshal(resp.Bytes(), &page); err != nil {
+			return nil, fmt.Errorf("error unmarshalling response: %w", err)
+		}
+
+		histories = append(histories, page.Values...)
+
+		if page.IsLast || len(page.Values) == 0 || len(histories) >= page.Total {
+			return histories, nil
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// newTestClient points a client at an httptest stand-in for Jira that
+// answers with the given statuses in turn, repeating the last one. It
+// returns the client and the number of requests the stand-in received.
+func newTestClient(t *testing.T, maxAttempts int, retryAfter string, statuses ...int) (*Client, *atomic.Int32) {
+	t.Helper()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		i := int(requests.Add(1)) - 1
+		status := statuses[min(i, len(statuses)-1)]
+
+		w.Header().Set("Content-Type", "application/json")
+		if status == http.StatusTooManyRequests && retryAfter != "" {
+			w.Header().Set("Retry-After", retryAfter)
+		}
+		w.WriteHeader(status)
+		if status >= 400 {
+			w.Write([]byte(`{"errorMessages":["failed"]}`))
+			return
+		}
+		w.Write([]byte(`{"displayName":"Ada"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient(Config{
+		BaseURL: server.URL,
+		Email:   "ada@example.com",
+		Token:   "token",
+		Retry: RetryPolicy{
+			MaxAttempts:    maxAttempts,
+			InitialBackoff: time.Millisecond,
+			MaxBackoff:     5 * time.Millisecond,
+		},
+	})
+	return client, &requests
+}
+
+func TestClientRetries(t *testing.T) {
+	tests := []struct {
+		name       string
+		statuses   []int
+		retryAfter string
+		wantErr    int // Status of the error, 0 for none
+		wantCalls  int32
+		wantStats  RetryStats
+		minElapsed time.Duration
+	}{
+		{
+			name:       "rate limited honours Retry-After",
+			statuses:   []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter: "1",
+			wantCalls:  2,
+			wantStats:  RetryStats{Total: 1, RateLimited: 1},
+			minElapsed: 900 * time.Millisecond,
+		},
+		{
+			name:      "service unavailable",
+			statuses:  []int{http.StatusServiceUnavailable, http.StatusOK},
+			wantCalls: 2,
+			wantStats: RetryStats{Total: 1, ServerError: 1},
+		},
+		{
+			name:      "not found isn't retried",
+			statuses:  []int{http.StatusNotFound},
+			wantErr:   http.StatusNotFound,
+			wantCalls: 1,
+		},
+		{
+			name:      "unauthorized isn't retried",
+			statuses:  []int{http.StatusUnauthorized},
+			wantErr:   http.StatusUnauthorized,
+			wantCalls: 1,
+		},
+		{
+			name:      "gives up after MaxAttempts",
+			statuses:  []int{http.StatusServiceUnavailable},
+			wantErr:   http.StatusServiceUnavailable,
+			wantCalls: 3,
+			wantStats: RetryStats{Total: 2, ServerError: 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, requests := newTestClient(t, 3, tt.retryAfter, tt.statuses...)
+
+			start := time.Now()
+			user, err := client.Myself(context.Background())
+			elapsed := time.Since(start)
+
+			if tt.wantErr == 0 {
+				if err != nil {
+					t.Fatalf("Myself() error = %v", err)
+				}
+				if user.DisplayName != "Ada" {
+					t.Errorf("DisplayName = %q, want Ada", user.DisplayName)
+				}
+			} else {
+				var apiErr *APIError
+				if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.wantErr {
+					t.Fatalf("Myself() error = %v, want status %d", err, tt.wantErr)
+				}
+				if len(apiErr.ErrorMessages) != 1 || apiErr.ErrorMessages[0] != "failed" {
+					t.Errorf("ErrorMessages = %v, want [failed]", apiErr.ErrorMessages)
+				}
+			}
+
+			if got := requests.Load(); got != tt.wantCalls {
+				t.Errorf("requests = %d, want %d", got, tt.wantCalls)
+			}
+			if got := client.RetryStats(); got != tt.wantStats {
+				t.Errorf("RetryStats() = %+v, want %+v", got, tt.wantStats)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("took %s, want at least %s", elapsed, tt.minElapsed)
+			}
+		})
+	}
+}
+
+func TestAPIErrorKind(t *testing.T) {
+	tests := []struct {
+		err  *APIError
+		want ErrorKind
+	}{
+		{&APIError{StatusCode: http.StatusUnauthorized}, ErrorKindAuth},
+		{&APIError{StatusCode: http.StatusForbidden}, ErrorKindPermission},
+		{&APIError{StatusCode: http.StatusNotFound}, ErrorKindNotFound},
+		{NotFoundError("ABC-1"), ErrorKindNotFound},
+		{&APIError{StatusCode: http.StatusBadRequest, Errors: map[string]string{"jql": "Issue does not exist"}}, ErrorKindNotFound},
+		{&APIError{StatusCode: http.StatusBadRequest, ErrorMessages: []string{"Error in the JQL Query"}}, ErrorKindBadRequest},
+		{&APIError{StatusCode: http.StatusGone}, ErrorKindBadRequest},
+		{&APIError{StatusCode: http.StatusTooManyRequests}, ErrorKindRateLimited},
+		{&APIError{StatusCode: http.StatusInternalServerError}, ErrorKindUpstreamDown},
+		{&APIError{StatusCode: http.StatusServiceUnavailable}, ErrorKindUpstreamDown},
+		{&APIError{StatusCode: http.StatusFound}, ErrorKindUnknown},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.err.Error(), func(t *testing.T) {
+			if got := tt.err.Kind(); got != tt.want {
+				t.Errorf("Kind() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListEpicRequestQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     ListEpicRequest
+		want    string
+		wantErr bool
+	}{
+		{name: "epic", req: ListEpicRequest{EpicID: "ABC-1"}, want: "parent = ABC-1"},
+		{name: "keys", req: ListEpicRequest{EpicID: "ABC-1", Keys: []string{"ABC-2", "my_proj-10"}}, want: "key in (ABC-2,my_proj-10)"},
+		{name: "jql", req: ListEpicRequest{JQL: "project = ABC"}, want: "project = ABC"},
+		{name: "injected epic", req: ListEpicRequest{EpicID: "ABC-1 OR project = X"}, wantErr: true},
+		{name: "injected key", req: ListEpicRequest{Keys: []string{"ABC-2", "ABC-3) OR (project = X"}}, wantErr: true},
+		{name: "empty epic", req: ListEpicRequest{}, wantErr: true},
+		{name: "epic without number", req: ListEpicRequest{EpicID: "ABC"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.req.Query()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidKey) {
+					t.Errorf("Query() = %q, %v, want ErrInvalidKey", got, err)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("Query() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestListEpicIssuesRejectsInvalidKeys(t *testing.T) {
+	client, requests := newTestClient(t, 1, "", http.StatusOK)
+
+	_, err := client.ListEpicIssues(context.Background(), ListEpicRequest{EpicID: "ABC-1 OR project = X"})
+	if !errors.Is(err, ErrInvalidKey) {
+		t.Errorf("ListEpicIssues() error = %v, want ErrInvalidKey", err)
+	}
+	if got := requests.Load(); got != 0 {
+		t.Errorf("requests = %d, want none", got)
+	}
+}
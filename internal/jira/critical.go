@@ -0,0 +1,267 @@
+package jira
+
+import (
+	"slices"
+	"strings"
+)
+
+// PathWeight is what the length of a blocking chain is measured in
+type PathWeight string
+
+const (
+	WeightPoints PathWeight = "points"
+	WeightCount  PathWeight = "count"
+)
+
+// CriticalPath is the longest chain of unfinished blocking work in an epic.
+// Path runs from the first blocker to the last blocked issue and Length is
+// its total weight. Slack is how much each unfinished issue could slip, in
+// the same unit, before it lengthens the critical path; it's 0 along it.
+// BlockedInProgress lists issues being worked on while one of their
+// blockers isn't done yet.
+type CriticalPath struct {
+	Weight            PathWeight         `json:"weight"`
+	Path              []string           `json:"path"`
+	Length            float64            `json:"length"`
+	Slack             map[string]float64 `json:"slack"`
+	BlockedInProgress []string           `json:"blockedInProgress"`
+}
+
+// isBlocking tells whether edges of the link type point from the blocker to
+// the blocked issue.
+func isBlocking(linkType string) bool {
+	return strings.EqualFold(linkType, "blocks")
+}
+
+// linkedIssues returns the status classification and story points of every
+// issue in the graph, including the ones outside the epic that are only
+// known through their links. Link edges carry the status of their linked
+// issue, which is the only place those can be read from; the other end of a
+// link is always an issue of the epic.
+func linkedIssues(graph Graph) (map[string]string, map[string]float64) {
+	classification := make(map[string]string)
+	storyPoints := make(map[string]float64)
+	for _, edge := range graph.Edges {
+		if edge.Type == "epic link" {
+			continue
+		}
+		for _, key := range []string{edge.From, edge.To} {
+			if _, ok := classification[key]; !ok {
+				classification[key] = edge.Classification
+				storyPoints[key] = edge.StoryPoints
+			}
+		}
+	}
+	for _, node := range graph.Nodes {
+		classification[node.ID] = node.Classification
+		storyPoints[node.ID] = node.StoryPoints
+	}
+	return classification, storyPoints
+}
+
+// FindCriticalPath finds the longest chain of unfinished issues linked by
+// "blocks" edges in the graph. Issues linked from outside the epic take part
+// with the status their link reports. Issues in, or blocked through, a
+// blocking cycle have no longest chain and are left out.
+func FindCriticalPath(graph Graph, weight PathWeight) CriticalPath {
+	result := CriticalPath{
+		Weight:            weight,
+		Path:              []string{},
+		Slack:             make(map[string]float64),
+		BlockedInProgress: []string{},
+	}
+
+	classification, storyPoints := linkedIssues(graph)
+
+	unfinished := func(key string) bool {
+		category := classification[key]
+		return category != "done" && category != "epic"
+	}
+
+	// Blocking edges between unfinished issues, once per pair of issues even
+	// when they are linked more than once.
+	successors := make(map[string][]string)
+	predecessors := make(map[string][]string)
+	blockedInProgress := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		if !isBlocking(edge.Type) || edge.From == edge.To {
+			continue
+		}
+		if !unfinished(edge.From) || !unfinished(edge.To) {
+			continue
+		}
+		if slices.Contains(successors[edge.From], edge.To) {
+			continue
+		}
+		successors[edge.From] = append(successors[edge.From], edge.To)
+		predecessors[edge.To] = append(predecessors[edge.To], edge.From)
+
+		if classification[edge.To] == "indeterminate" {
+			blockedInProgress[edge.To] = true
+		}
+	}
+
+	for key := range blockedInProgress {
+		result.BlockedInProgress = append(result.BlockedInProgress, key)
+	}
+	slices.SortFunc(result.BlockedInProgress, CompareKeys)
+
+	// Issues of the epic, and the ones outside it that block or are blocked
+	// by them.
+	nodes := graph.NodesByID()
+	keys := make([]string, 0, len(classification))
+	for key := range classification {
+		if _, ok := nodes[key]; unfinished(key) && (ok || len(successors[key]) > 0 || len(predecessors[key]) > 0) {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, CompareKeys)
+
+	order := topologicalOrder(keys, successors, predecessors)
+
+	cost := func(key string) float64 {
+		if weight == WeightPoints {
+			return storyPoints[key]
+		}
+		return 1
+	}
+
+	// Forward pass: the heaviest chain ending at each issue, with ties going
+	// to the chain with more issues.
+	finish := make(map[string]float64, len(order))
+	hops := make(map[string]int, len(order))
+	previous := make(map[string]string, len(order))
+	for _, key := range order {
+		for _, predecessor := range predecessors[key] {
+			if _, ok := finish[predecessor]; !ok {
+				continue
+			}
+			if finish[predecessor] > finish[key] ||
+				(finish[predecessor] == finish[key] && hops[predecessor]+1 > hops[key]) {
+				finish[key] = finish[predecessor]
+				hops[key] = hops[predecessor] + 1
+				previous[key] = predecessor
+			}
+		}
+		finish[key] += cost(key)
+	}
+
+	var last string
+	for _, key := range order {
+		if last == "" || finish[key] > finish[last] ||
+			(finish[key] == finish[last] && hops[key] > hops[last]) {
+			last = key
+		}
+	}
+	if last == "" {
+		return result
+	}
+
+	result.Length = finish[last]
+	for key := last; key != ""; key = previous[key] {
+		result.Path = append(result.Path, key)
+	}
+	slices.Reverse(result.Path)
+
+	// Backward pass: the latest each issue can finish without delaying the
+	// end of the critical path.
+	latest := make(map[string]float64, len(order))
+	for i := len(order) - 1; i >= 0; i-- {
+		key := order[i]
+		latest[key] = result.Length
+		for _, successor := range successors[key] {
+			if _, ok := latest[successor]; !ok {
+				continue
+			}
+			latest[key] = min(latest[key], latest[successor]-cost(successor))
+		}
+		result.Slack[key] = max(latest[key]-finish[key], 0)
+	}
+	for _, key := range result.Path {
+		result.Slack[key] = 0
+	}
+
+	return result
+}
+
+// topologicalOrder sorts keys so that every issue comes after its blockers,
+// leaving out the ones that can't be ordered because of a cycle.
+func topologicalOrder(keys []string, successors map[string][]string, predecessors map[string][]string) []string {
+	pending := make(map[string]int, len(keys))
+	var queue []string
+	for _, key := range keys {
+		pending[key] = len(predecessors[key])
+		if pending[key] == 0 {
+			queue = append(queue, key)
+		}
+	}
+
+	order := make([]string, 0, len(keys))
+	for len(queue) > 0 {
+		key := queue[0]
+		queue = queue[1:]
+		order = append(order, key)
+
+		next := slices.Clone(successors[key])
+		slices.SortFunc(next, CompareKeys)
+		for _, successor := range next {
+			pending[successor]--
+			if pending[successor] == 0 {
+				queue = append(queue, successor)
+			}
+		}
+	}
+
+	return order
+}
+
+// BlockedIssue is an unfinished issue of the epic waiting on blockers that
+// aren't done yet.
+type BlockedIssue struct {
+	Key      string   `json:"key"`
+	Summary  string   `json:"summary"`
+	Status   string   `json:"status"`
+	Blockers []string `json:"blockers"`
+}
+
+// BlockedIssues lists the unfinished issues of the epic that have at least
+// one unfinished blocker, sorted by key. Blockers outside the epic count
+// with the status their link reports.
+func BlockedIssues(graph Graph) []BlockedIssue {
+	classification, _ := linkedIssues(graph)
+	nodes := graph.NodesByID()
+
+	unfinished := func(key string) bool {
+		category := classification[key]
+		return category != "done" && category != "epic"
+	}
+
+	blockers := make(map[string][]string)
+	for _, edge := range graph.Edges {
+		if !isBlocking(edge.Type) || edge.From == edge.To {
+			continue
+		}
+		if node, ok := nodes[edge.To]; !ok || node.External {
+			continue
+		}
+		if unfinished(edge.From) && unfinished(edge.To) && !slices.Contains(blockers[edge.To], edge.From) {
+			blockers[edge.To] = append(blockers[edge.To], edge.From)
+		}
+	}
+
+	blocked := make([]BlockedIssue, 0, len(blockers))
+	for key, from := range blockers {
+		slices.SortFunc(from, CompareKeys)
+		blocked = append(blocked, BlockedIssue{
+			Key:      key,
+			Summary:  nodes[key].Label,
+			Status:   nodes[key].Status,
+			Blockers: from,
+		})
+	}
+	slices.SortFunc(blocked, func(a, b BlockedIssue) int {
+		return CompareKeys(a.Key, b.Key)
+	})
+
+	return blocked
+}
@@ -0,0 +1,118 @@
+package jira
+
+import (
+	"reflect"
+	"testing"
+)
+
+// blockingGraph builds a graph of the given issues linked by "Blocks" edges,
+// each from the blocker to the blocked issue
+func blockingGraph(nodes []GraphNode, edges [][2]string) Graph {
+	graph := Graph{Nodes: nodes}
+	for _, edge := range edges {
+		graph.Edges = append(graph.Edges, GraphEdge{From: edge[0], To: edge[1], Type: "Blocks"})
+	}
+	return graph
+}
+
+// issue is a node of the epic with the given status classification
+func issue(key string, classification string, points float64) GraphNode {
+	return GraphNode{ID: key, Classification: classification, StoryPoints: points, Epic: "ABC-100"}
+}
+
+func TestFindCriticalPath(t *testing.T) {
+	diamond := blockingGraph(
+		[]GraphNode{issue("ABC-1", "new", 1), issue("ABC-2", "new", 3), issue("ABC-3", "new", 1), issue("ABC-4", "new", 2)},
+		[][2]string{{"ABC-1", "ABC-2"}, {"ABC-1", "ABC-3"}, {"ABC-2", "ABC-4"}, {"ABC-3", "ABC-4"}},
+	)
+
+	tests := []struct {
+		name   string
+		graph  Graph
+		weight PathWeight
+		want   CriticalPath
+	}{
+		{
+			name:   "empty",
+			graph:  Graph{},
+			weight: WeightPoints,
+			want:   CriticalPath{Path: []string{}, Slack: map[string]float64{}, BlockedInProgress: []string{}},
+		},
+		{
+			name:   "diamond by points",
+			graph:  diamond,
+			weight: WeightPoints,
+			want: CriticalPath{
+				Path:              []string{"ABC-1", "ABC-2", "ABC-4"},
+				Length:            6,
+				Slack:             map[string]float64{"ABC-1": 0, "ABC-2": 0, "ABC-3": 2, "ABC-4": 0},
+				BlockedInProgress: []string{},
+			},
+		},
+		{
+			name:   "diamond by count",
+			graph:  diamond,
+			weight: WeightCount,
+			want: CriticalPath{
+				Path:              []string{"ABC-1", "ABC-2", "ABC-4"},
+				Length:            3,
+				Slack:             map[string]float64{"ABC-1": 0, "ABC-2": 0, "ABC-3": 0, "ABC-4": 0},
+				BlockedInProgress: []string{},
+			},
+		},
+		{
+			name: "tie goes to the lowest issue key",
+			graph: blockingGraph(
+				[]GraphNode{issue("ABC-10", "new", 1), issue("ABC-11", "new", 1), issue("ABC-12", "new", 1), issue("ABC-9", "new", 1)},
+				[][2]string{{"ABC-10", "ABC-12"}, {"ABC-9", "ABC-11"}},
+			),
+			weight: WeightCount,
+			want: CriticalPath{
+				Path:              []string{"ABC-9", "ABC-11"},
+				Length:            2,
+				Slack:             map[string]float64{"ABC-9": 0, "ABC-10": 0, "ABC-11": 0, "ABC-12": 0},
+				BlockedInProgress: []string{},
+			},
+		},
+		{
+			name: "cycle is left out",
+			graph: blockingGraph(
+				[]GraphNode{issue("ABC-1", "new", 1), issue("ABC-2", "new", 1), issue("ABC-3", "new", 1), issue("ABC-4", "new", 1), issue("ABC-5", "new", 1)},
+				[][2]string{{"ABC-1", "ABC-2"}, {"ABC-2", "ABC-1"}, {"ABC-2", "ABC-3"}, {"ABC-4", "ABC-5"}},
+			),
+			weight: WeightCount,
+			want: CriticalPath{
+				Path:              []string{"ABC-4", "ABC-5"},
+				Length:            2,
+				Slack:             map[string]float64{"ABC-4": 0, "ABC-5": 0},
+				BlockedInProgress: []string{},
+			},
+		},
+		{
+			name: "finished blocker",
+			graph: blockingGraph(
+				[]GraphNode{
+					issue("ABC-1", "done", 5), issue("ABC-2", "indeterminate", 2), issue("ABC-3", "new", 1),
+					issue("ABC-4", "new", 3), issue("ABC-10", "indeterminate", 1),
+				},
+				[][2]string{{"ABC-1", "ABC-2"}, {"ABC-3", "ABC-2"}, {"ABC-2", "ABC-4"}, {"ABC-3", "ABC-10"}},
+			),
+			weight: WeightPoints,
+			want: CriticalPath{
+				Path:              []string{"ABC-3", "ABC-2", "ABC-4"},
+				Length:            6,
+				Slack:             map[string]float64{"ABC-2": 0, "ABC-3": 0, "ABC-4": 0, "ABC-10": 4},
+				BlockedInProgress: []string{"ABC-2", "ABC-10"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.want.Weight = tt.weight
+			if got := FindCriticalPath(tt.graph, tt.weight); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("FindCriticalPath() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
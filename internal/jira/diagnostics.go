@@ -0,0 +1,211 @@
+package jira
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+type WarningKind string
+
+const (
+	// WarningCycle is a set of issues that block each other
+	WarningCycle WarningKind = "cycle"
+	// WarningResolvedBlocker is a blocker that is done while the issue of
+	// the epic it blocks hasn't started yet
+	WarningResolvedBlocker WarningKind = "resolved-blocker"
+	// WarningExternalLink is a link to an issue outside the epic
+	WarningExternalLink WarningKind = "external-link"
+	// WarningOrphan is an issue linked to nothing else in an epic whose other
+	// issues are linked together
+	WarningOrphan WarningKind = "orphan"
+)
+
+// Warning is an inconsistency in the links of an epic. Issues lists the
+// issues involved, blocker first for links.
+type Warning struct {
+	Kind    WarningKind `json:"kind"`
+	Message string      `json:"message"`
+	Issues  []string    `json:"issues"`
+}
+
+// Diagnose checks the links of the graph for blocking cycles, stale
+// blockers, links leaving the epic and orphaned issues. Warnings come in
+// that order, each kind sorted by issue key.
+func Diagnose(graph Graph) []Warning {
+	warnings := []Warning{}
+
+	classification, _ := linkedIssues(graph)
+	nodes := graph.NodesByID()
+	inEpic := func(key string) bool {
+		node, ok := nodes[key]
+		return ok && !node.External
+	}
+
+	type link struct{ from, to, kind string }
+	var links []link
+	seen := make(map[link]bool)
+	blocks := make(map[string][]string)
+	linked := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		if edge.Type == "epic link" {
+			continue
+		}
+
+		l := link{edge.From, edge.To, edge.Type}
+		if seen[l] {
+			continue
+		}
+		seen[l] = true
+		links = append(links, l)
+
+		linked[edge.From] = true
+		linked[edge.To] = true
+		if isBlocking(edge.Type) {
+			blocks[edge.From] = append(blocks[edge.From], edge.To)
+		}
+	}
+	slices.SortFunc(links, func(a, b link) int {
+		return cmp.Or(
+			CompareKeys(a.from, b.from),
+			CompareKeys(a.to, b.to),
+			strings.Compare(a.kind, b.kind),
+		)
+	})
+
+	for _, cycle := range blockingCycles(blocks) {
+		message := fmt.Sprintf("%s block each other", strings.Join(cycle, ", "))
+		if len(cycle) == 1 {
+			message = fmt.Sprintf("%s blocks itself", cycle[0])
+		}
+
+		warnings = append(warnings, Warning{
+			Kind:    WarningCycle,
+			Message: message,
+			Issues:  cycle,
+		})
+	}
+
+	for _, l := range links {
+		if !isBlocking(l.kind) {
+			continue
+		}
+		if inEpic(l.to) && classification[l.from] == "done" && classification[l.to] == "new" {
+			warnings = append(warnings, Warning{
+				Kind:    WarningResolvedBlocker,
+				Message: fmt.Sprintf("%s is done but %s, which it blocks, hasn't started", l.from, l.to),
+				Issues:  []string{l.from, l.to},
+			})
+		}
+	}
+
+	for _, l := range links {
+		switch {
+		case inEpic(l.from) && !inEpic(l.to):
+			warnings = append(warnings, Warning{
+				Kind:    WarningExternalLink,
+				Message: fmt.Sprintf("%s %s %s, which is outside the epic", l.from, strings.ToLower(l.kind), l.to),
+				Issues:  []string{l.from, l.to},
+			})
+		case !inEpic(l.from) && inEpic(l.to):
+			warnings = append(warnings, Warning{
+				Kind:    WarningExternalLink,
+				Message: fmt.Sprintf("%s, which is outside the epic, %s %s", l.from, strings.ToLower(l.kind), l.to),
+				Issues:  []string{l.from, l.to},
+			})
+		}
+	}
+
+	// An epic without any links has no structure to be orphaned from
+	if len(links) > 0 {
+		var orphans []string
+		for _, node := range graph.Nodes {
+			if inEpic(node.ID) && node.Classification != "epic" && !linked[node.ID] {
+				orphans = append(orphans, node.ID)
+			}
+		}
+		slices.SortFunc(orphans, CompareKeys)
+
+		for _, orphan := range orphans {
+			warnings = append(warnings, Warning{
+				Kind:    WarningOrphan,
+				Message: fmt.Sprintf("%s isn't linked to any other issue", orphan),
+				Issues:  []string{orphan},
+			})
+		}
+	}
+
+	return warnings
+}
+
+// blockingCycles finds the strongly connected components of the blocking
+// relation with Tarjan's algorithm, and returns those that form a cycle:
+// more than one issue, or an issue blocking itself.
+func blockingCycles(blocks map[string][]string) [][]string {
+	keys := make([]string, 0, len(blocks))
+	for key, blocked := range blocks {
+		keys = append(keys, key)
+		slices.SortFunc(blocked, CompareKeys)
+	}
+	slices.SortFunc(keys, CompareKeys)
+
+	var (
+		index   int
+		indices = make(map[string]int)
+		lowlink = make(map[string]int)
+		onStack = make(map[string]bool)
+		stack   []string
+		cycles  [][]string
+	)
+
+	var connect func(key string)
+	connect = func(key string) {
+		indices[key] = index
+		lowlink[key] = index
+		index++
+		stack = append(stack, key)
+		onStack[key] = true
+
+		for _, blocked := range blocks[key] {
+			if _, visited := indices[blocked]; !visited {
+				connect(blocked)
+				lowlink[key] = min(lowlink[key], lowlink[blocked])
+			} else if onStack[blocked] {
+				lowlink[key] = min(lowlink[key], indices[blocked])
+			}
+		}
+
+		if lowlink[key] != indices[key] {
+			return
+		}
+
+		var component []string
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			component = append(component, top)
+			if top == key {
+				break
+			}
+		}
+
+		if len(component) > 1 || slices.Contains(blocks[key], key) {
+			slices.SortFunc(component, CompareKeys)
+			cycles = append(cycles, component)
+		}
+	}
+
+	for _, key := range keys {
+		if _, visited := indices[key]; !visited {
+			connect(key)
+		}
+	}
+
+	slices.SortFunc(cycles, func(a, b []string) int {
+		return slices.CompareFunc(a, b, CompareKeys)
+	})
+
+	return cycles
+}
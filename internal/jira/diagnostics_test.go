@@ -0,0 +1,49 @@
+package jira
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDiagnoseSortsByIssueKey(t *testing.T) {
+	node := func(key string, classification string) GraphNode {
+		return GraphNode{ID: key, Classification: classification, Epic: "ABC-1"}
+	}
+	blocks := func(from string, to string) GraphEdge {
+		return GraphEdge{From: from, To: to, Type: "Blocks"}
+	}
+
+	graph := Graph{
+		Nodes: []GraphNode{
+			{ID: "ABC-1", Classification: "epic"},
+			node("ABC-2", "done"), node("ABC-9", "done"), node("ABC-10", "done"),
+			node("ABC-3", "new"), node("ABC-11", "new"), node("ABC-12", "new"),
+		},
+		Edges: []GraphEdge{
+			// Cycles between ABC-11 and ABC-12, and ABC-3 and ABC-9
+			blocks("ABC-11", "ABC-12"), blocks("ABC-12", "ABC-11"),
+			blocks("ABC-9", "ABC-3"), blocks("ABC-3", "ABC-9"),
+			// Resolved blockers
+			blocks("ABC-10", "ABC-3"), blocks("ABC-2", "ABC-11"), blocks("ABC-9", "ABC-12"),
+		},
+	}
+
+	var cycles, resolved [][]string
+	for _, warning := range Diagnose(graph) {
+		switch warning.Kind {
+		case WarningCycle:
+			cycles = append(cycles, warning.Issues)
+		case WarningResolvedBlocker:
+			resolved = append(resolved, warning.Issues)
+		}
+	}
+
+	wantCycles := [][]string{{"ABC-3", "ABC-9"}, {"ABC-11", "ABC-12"}}
+	if !slices.EqualFunc(cycles, wantCycles, slices.Equal) {
+		t.Errorf("cycles = %v, want %v", cycles, wantCycles)
+	}
+	wantResolved := [][]string{{"ABC-2", "ABC-11"}, {"ABC-9", "ABC-3"}, {"ABC-9", "ABC-12"}, {"ABC-10", "ABC-3"}}
+	if !slices.EqualFunc(resolved, wantResolved, slices.Equal) {
+		t.Errorf("resolved blockers = %v, want %v", resolved, wantResolved)
+	}
+}
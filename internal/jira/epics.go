@@ -0,0 +1,82 @@
+package jira
+
+import (
+	"slices"
+)
+
+// EpicDependency is another epic linked to ours by blocking links. Issues
+// are its issues taking part in them, and Open counts the links whose
+// blocker isn't done yet.
+type EpicDependency struct {
+	Epic   string   `json:"epic"` // Key of the other epic, empty when unknown
+	Issues []string `json:"issues"`
+	Links  int      `json:"links"`
+	Open   int      `json:"open"`
+}
+
+// EpicDependencies summarizes which other epics ours is blocked by, and which
+// ones it blocks.
+type EpicDependencies struct {
+	BlockedBy []EpicDependency `json:"blockedBy"`
+	Blocks    []EpicDependency `json:"blocks"`
+}
+
+// SummarizeEpicDependencies groups the blocking links between the epic and
+// issues outside of it by the epic of the outside issue. That epic is only
+// known for expanded issues; the others are grouped under an empty key.
+func SummarizeEpicDependencies(graph Graph) EpicDependencies {
+	nodes := graph.NodesByID()
+	classification, _ := linkedIssues(graph)
+
+	inEpic := func(key string) bool {
+		node, ok := nodes[key]
+		return ok && !node.External
+	}
+
+	blockedBy := make(map[string]*EpicDependency)
+	blocks := make(map[string]*EpicDependency)
+	for _, edge := range graph.Edges {
+		if !isBlocking(edge.Type) || inEpic(edge.From) == inEpic(edge.To) {
+			continue
+		}
+
+		summary, other := blockedBy, edge.From
+		if inEpic(edge.From) {
+			summary, other = blocks, edge.To
+		}
+
+		epic := nodes[other].Epic
+		dependency, ok := summary[epic]
+		if !ok {
+			dependency = &EpicDependency{Epic: epic, Issues: []string{}}
+			summary[epic] = dependency
+		}
+
+		dependency.Links++
+		if classification[edge.From] != "done" {
+			dependency.Open++
+		}
+		if !slices.Contains(dependency.Issues, other) {
+			dependency.Issues = append(dependency.Issues, other)
+		}
+	}
+
+	return EpicDependencies{
+		BlockedBy: sortedDependencies(blockedBy),
+		Blocks:    sortedDependencies(blocks),
+	}
+}
+
+func sortedDependencies(byEpic map[string]*EpicDependency) []EpicDependency {
+	dependencies := make([]EpicDependency, 0, len(byEpic))
+	for _, dependency := range byEpic {
+		slices.SortFunc(dependency.Issues, CompareKeys)
+		dependencies = append(dependencies, *dependency)
+	}
+
+	slices.SortFunc(dependencies, func(a, b EpicDependency) int {
+		return CompareKeys(a.Epic, b.Epic)
+	})
+
+	return dependencies
+}
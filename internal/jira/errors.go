@@ -0,0 +1,117 @@
+package jira
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	cliutils "github.com/Fuabioo/altalune/pkg/cliutls"
+
+	"resty.dev/v3"
+)
+
+// ErrorKind classifies an APIError by what the user can do about it.
+type ErrorKind string
+
+const (
+	ErrorKindAuth         ErrorKind = "auth"          // Missing, invalid or expired credentials
+	ErrorKindPermission   ErrorKind = "permission"    // Authenticated but not allowed
+	ErrorKindNotFound     ErrorKind = "not-found"     // Issue or resource does not exist
+	ErrorKindRateLimited  ErrorKind = "rate-limited"  // Too many requests
+	ErrorKindUpstreamDown ErrorKind = "upstream-down" // Jira is failing or unavailable
+	ErrorKindBadRequest   ErrorKind = "bad-request"   // Jira rejected the request, e.g. invalid JQL
+	ErrorKindUnknown      ErrorKind = "unknown"
+)
+
+// APIError is a non-2xx response from Jira.
+type APIError struct {
+	StatusCode    int
+	ErrorMessages []string
+	Errors        map[string]string
+	// RetryAfter is the raw Retry-After header, if Jira sent one.
+	RetryAfter string
+}
+
+// Kind classifies the error. Jira answers a JQL query on a missing issue
+// with a 400 rather than a 404, so those are reported as not found too.
+func (e *APIError) Kind() ErrorKind {
+	switch {
+	case e.StatusCode == http.StatusUnauthorized:
+		return ErrorKindAuth
+	case e.StatusCode == http.StatusForbidden:
+		return ErrorKindPermission
+	case e.StatusCode == http.StatusNotFound:
+		return ErrorKindNotFound
+	case e.StatusCode == http.StatusTooManyRequests:
+		return ErrorKindRateLimited
+	case e.StatusCode >= 500:
+		return ErrorKindUpstreamDown
+	case e.StatusCode == http.StatusBadRequest && e.mentions("does not exist"):
+		return ErrorKindNotFound
+	case e.StatusCode >= 400:
+		return ErrorKindBadRequest
+	default:
+		return ErrorKindUnknown
+	}
+}
+
+// Messages returns every message Jira sent, field errors included.
+func (e *APIError) Messages() []string {
+	messages := append([]string{}, e.ErrorMessages...)
+	for field, message := range e.Errors {
+		messages = append(messages, fmt.Sprintf("%s: %s", field, message))
+	}
+	return messages
+}
+
+func (e *APIError) mentions(substrings ...string) bool {
+	for _, message := range e.Messages() {
+		if cliutils.Contains(message, substrings...) {
+			return true
+		}
+	}
+	return false
+}
+
+// NotFoundError builds the error Jira returns when an issue key is unknown.
+func NotFoundError(key string) *APIError {
+	return &APIError{
+		StatusCode:    http.StatusBadRequest,
+		ErrorMessages: []string{fmt.Sprintf("An issue with key '%s' does not exist", key)},
+	}
+}
+
+func (e *APIError) Error() string {
+	messages := e.Messages()
+
+	if len(messages) == 0 {
+		return fmt.Sprintf("jira responded with status %d", e.StatusCode)
+	}
+
+	return fmt.Sprintf("jira responded with status %d: %s", e.StatusCode, strings.Join(messages, "; "))
+}
+
+// checkResponse turns non-2xx responses into an *APIError carrying the
+// errorMessages and errors Jira sends in the body.
+func checkResponse(resp *resty.Response) error {
+	if resp.StatusCode() >= 200 && resp.StatusCode() < 300 {
+		return nil
+	}
+
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode(),
+		RetryAfter: resp.Header().Get("Retry-After"),
+	}
+
+	var body struct {
+		ErrorMessages []string          `json:"errorMessages"`
+		Errors        map[string]string `json:"errors"`
+	}
+	if err := json.Unmarshal(resp.Bytes(), &body); err == nil {
+		apiErr.ErrorMessages = body.ErrorMessages
+		apiErr.Errors = body.Errors
+	}
+
+	return apiErr
+}
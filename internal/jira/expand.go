@@ -0,0 +1,132 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/Fuabioo/altalune/internal/model"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// ExpandLinkedIssues fetches the issues outside the epic that its issues
+// link to, then the ones those link to, up to hops links away. req carries
+// the page size and fields; its epic is ignored.
+//
+// Jira rejects a whole "key in (...)" query when one of the keys doesn't
+// exist or can't be browsed, so a batch failing that way is retried one
+// issue at a time and the missing issues are left out.
+func ExpandLinkedIssues(ctx context.Context, source IssueSource, issues []*model.Ticket, hops int, req ListEpicRequest, concurrency int) ([]*model.Ticket, error) {
+	known := make(map[string]bool, len(issues))
+	for _, issue := range issues {
+		known[issue.Key] = true
+	}
+
+	var linked []*model.Ticket
+	frontier := issues
+	for hop := 0; hop < hops; hop++ {
+		keys := unknownLinks(frontier, known)
+		if len(keys) == 0 {
+			break
+		}
+
+		fetched, err := fetchIssuesByKey(ctx, source, req, keys, concurrency)
+		if err != nil {
+			return nil, err
+		}
+
+		log.Debug("Expanded linked issues",
+			"hop", hop+1,
+			"requested", len(keys),
+			"fetched", len(fetched),
+		)
+
+		for _, key := range keys {
+			known[key] = true
+		}
+		linked = append(linked, fetched...)
+		frontier = fetched
+	}
+
+	return linked, nil
+}
+
+// unknownLinks returns the keys of the issues linked from issues that aren't
+// known yet, sorted.
+func unknownLinks(issues []*model.Ticket, known map[string]bool) []string {
+	var keys []string
+	for _, issue := range issues {
+		for _, link := range issue.Fields.IssueLinks {
+			for _, key := range []string{link.OutwardIssue.Key, link.InwardIssue.Key} {
+				if key != "" && !known[key] && !slices.Contains(keys, key) {
+					keys = append(keys, key)
+				}
+			}
+		}
+	}
+
+	slices.SortFunc(keys, CompareKeys)
+	return keys
+}
+
+func fetchIssuesByKey(ctx context.Context, source IssueSource, req ListEpicRequest, keys []string, concurrency int) ([]*model.Ticket, error) {
+	req.EpicID = ""
+	req.NextPageToken = ""
+	req.StartAt = 0
+
+	requests := keyRequests(req, keys)
+	pages := make([][]*model.Ticket, len(requests))
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(max(concurrency, 1))
+
+	for i, page := range requests {
+		group.Go(func() error {
+			issues, err := ListAllEpicIssues(ctx, source, page)
+			if isNotFound(err) {
+				issues, err = fetchEachKey(ctx, source, page)
+			}
+			if err != nil {
+				return err
+			}
+
+			pages[i] = orderByKeys(issues, page.Keys)
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return slices.Concat(pages...), nil
+}
+
+// fetchEachKey fetches the issues of req one by one, skipping the ones that
+// don't exist or can't be browsed.
+func fetchEachKey(ctx context.Context, source IssueSource, req ListEpicRequest) ([]*model.Ticket, error) {
+	var issues []*model.Ticket
+	for _, key := range req.Keys {
+		single := req
+		single.Keys = []string{key}
+		single.PageSize = 1
+
+		found, err := ListAllEpicIssues(ctx, source, single)
+		if isNotFound(err) {
+			log.Debug("Skipping linked issue", "key", key, "err", err)
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, found...)
+	}
+	return issues, nil
+}
+
+func isNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.Kind() == ErrorKindNotFound
+}
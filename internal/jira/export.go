@@ -0,0 +1,354 @@
+package jira
+
+import (
+	"bufio"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// GraphFormat is a serialization of a dependency graph
+type GraphFormat string
+
+const (
+	GraphJSON    GraphFormat = "json"
+	GraphDOT     GraphFormat = "dot"
+	GraphMermaid GraphFormat = "mermaid"
+	GraphML      GraphFormat = "graphml"
+)
+
+// GraphFormats lists every supported format
+var GraphFormats = []GraphFormat{GraphJSON, GraphDOT, GraphMermaid, GraphML}
+
+// ParseGraphFormat validates a format name
+func ParseGraphFormat(name string) (GraphFormat, error) {
+	for _, format := range GraphFormats {
+		if strings.EqualFold(name, string(format)) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown graph format %q, expected one of json, dot, mermaid or graphml", name)
+}
+
+// ContentType is the media type documents of the format are served as
+func (f GraphFormat) ContentType() string {
+	switch f {
+	case GraphDOT:
+		return "text/vnd.graphviz; charset=utf-8"
+	case GraphMermaid:
+		return "text/plain; charset=utf-8"
+	case GraphML:
+		return "application/graphml+xml; charset=utf-8"
+	default:
+		return "application/json"
+	}
+}
+
+// Extension is the file extension of the format
+func (f GraphFormat) Extension() string {
+	switch f {
+	case GraphDOT:
+		return ".dot"
+	case GraphMermaid:
+		return ".mmd"
+	case GraphML:
+		return ".graphml"
+	default:
+		return ".json"
+	}
+}
+
+// classificationColors are the fill colors of nodes by status
+// classification, matching the dashboard's palette.
+var classificationColors = map[string]string{
+	"epic":          "#c6a0f6",
+	"new":           "#cad3f5",
+	"indeterminate": "#8aadf4",
+	"done":          "#a6da95",
+}
+
+const unknownColor = "#939ab7"
+
+func classificationColor(classification string) string {
+	if color, ok := classificationColors[classification]; ok {
+		return color
+	}
+	return unknownColor
+}
+
+// WriteGraph serializes the graph in the given format
+func WriteGraph(w io.Writer, graph Graph, format GraphFormat) error {
+	switch format {
+	case GraphJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(graph)
+	case GraphDOT:
+		return WriteDOT(w, graph)
+	case GraphMermaid:
+		return WriteMermaid(w, graph)
+	case GraphML:
+		return WriteGraphML(w, graph)
+	default:
+		return fmt.Errorf("unknown graph format %q", format)
+	}
+}
+
+// nodeLabel is the key, summary and story points of the node, one per line
+func nodeLabel(node GraphNode) []string {
+	lines := []string{node.ID}
+	if node.Label != "" && node.Classification != "epic" {
+		lines = append(lines, node.Label)
+	}
+	if node.StoryPoints > 0 {
+		lines = append(lines, strconv.FormatFloat(node.StoryPoints, 'f', -1, 64)+" pts")
+	}
+	return lines
+}
+
+// exportedNodes returns the graph's nodes followed by stubs for the issues
+// outside the epic that are only known through links, so that every edge
+// ends at a declared node.
+func exportedNodes(graph Graph) []GraphNode {
+	nodes := slices.Clone(graph.Nodes)
+	known := graph.NodesByID()
+	classification, storyPoints := linkedIssues(graph)
+
+	for _, edge := range graph.Edges {
+		for _, key := range []string{edge.From, edge.To} {
+			if _, ok := known[key]; ok {
+				continue
+			}
+
+			// The edge describes its linked issue, which is this one
+			stub := GraphNode{
+				ID:             key,
+				Status:         edge.Status,
+				Classification: classification[key],
+				StoryPoints:    storyPoints[key],
+				External:       true,
+			}
+			known[key] = stub
+			nodes = append(nodes, stub)
+		}
+	}
+
+	return nodes
+}
+
+// graphName is the key of the graph's epic
+func graphName(graph Graph) string {
+	for _, node := range graph.Nodes {
+		if node.Classification == "epic" {
+			return node.ID
+		}
+	}
+	return "dependencies"
+}
+
+// WriteDOT writes the graph in Graphviz DOT. Nodes are filled by status
+// classification, epic links are dashed and blocking links red.
+func WriteDOT(w io.Writer, graph Graph) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "digraph %s {\n", dotQuote(graphName(graph)))
+	fmt.Fprintln(out, "  rankdir=LR;")
+	fmt.Fprintln(out, `  node [shape=box, style="rounded,filled", fontname="Helvetica"];`)
+	fmt.Fprintln(out, `  edge [fontname="Helvetica", fontsize=10];`)
+
+	for _, node := range exportedNodes(graph) {
+		attributes := []string{
+			"label=" + dotQuote(strings.Join(nodeLabel(node), "\n")),
+			"fillcolor=" + dotQuote(classificationColor(node.Classification)),
+		}
+		if node.External {
+			attributes = append(attributes, `style="rounded,filled,dashed"`)
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", dotQuote(node.ID), strings.Join(attributes, ", "))
+	}
+
+	for _, edge := range graph.Edges {
+		var attributes []string
+		switch {
+		case edge.Type == "epic link":
+			attributes = []string{"style=dashed", `color="#939ab7"`}
+		case isBlocking(edge.Type):
+			attributes = []string{`color="#ed8796"`, "label=" + dotQuote("blocks")}
+		default:
+			attributes = []string{"label=" + dotQuote(strings.ToLower(edge.Type))}
+		}
+		fmt.Fprintf(out, "  %s -> %s [%s];\n", dotQuote(edge.From), dotQuote(edge.To), strings.Join(attributes, ", "))
+	}
+
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\r", "")
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart, with a class per
+// status classification.
+func WriteMermaid(w io.Writer, graph Graph) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintln(out, "flowchart LR")
+
+	nodes := exportedNodes(graph)
+	for _, node := range nodes {
+		fmt.Fprintf(out, "  %s[\"%s\"]\n", mermaidID(node.ID), mermaidText(strings.Join(nodeLabel(node), "\n")))
+	}
+
+	for _, edge := range graph.Edges {
+		from, to := mermaidID(edge.From), mermaidID(edge.To)
+		switch {
+		case edge.Type == "epic link":
+			fmt.Fprintf(out, "  %s -.-> %s\n", from, to)
+		case isBlocking(edge.Type):
+			fmt.Fprintf(out, "  %s -->|blocks| %s\n", from, to)
+		default:
+			fmt.Fprintf(out, "  %s ---|%s| %s\n", from, mermaidText(strings.ToLower(edge.Type)), to)
+		}
+	}
+
+	for _, classification := range []string{"epic", "new", "indeterminate", "done"} {
+		fmt.Fprintf(out, "  classDef %s fill:%s,stroke:#494d64,color:#24273a\n", classification, classificationColor(classification))
+	}
+	for _, node := range nodes {
+		if _, ok := classificationColors[node.Classification]; ok {
+			fmt.Fprintf(out, "  class %s %s\n", mermaidID(node.ID), node.Classification)
+		}
+	}
+
+	return out.Flush()
+}
+
+// mermaidID turns an issue key into a node ID, as dashes would read as
+// links.
+func mermaidID(key string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+// mermaidText escapes text for a quoted label. Mermaid renders labels as
+// HTML, so markup is escaped too and line breaks become <br/>.
+func mermaidText(s string) string {
+	return strings.NewReplacer(
+		`"`, "#quot;",
+		"|", "#124;",
+		"&", "#amp;",
+		"<", "#lt;",
+		">", "#gt;",
+		"\r", "",
+		"\n", "<br/>",
+	).Replace(s)
+}
+
+type (
+	graphML struct {
+		XMLName xml.Name     `xml:"graphml"`
+		Xmlns   string       `xml:"xmlns,attr"`
+		Keys    []graphMLKey `xml:"key"`
+		Graph   graphMLGraph `xml:"graph"`
+	}
+	graphMLKey struct {
+		ID   string `xml:"id,attr"`
+		For  string `xml:"for,attr"`
+		Name string `xml:"attr.name,attr"`
+		Type string `xml:"attr.type,attr"`
+	}
+	graphMLGraph struct {
+		ID          string        `xml:"id,attr"`
+		EdgeDefault string        `xml:"edgedefault,attr"`
+		Nodes       []graphMLNode `xml:"node"`
+		Edges       []graphMLEdge `xml:"edge"`
+	}
+	graphMLNode struct {
+		ID   string        `xml:"id,attr"`
+		Data []graphMLData `xml:"data"`
+	}
+	graphMLEdge struct {
+		ID     string        `xml:"id,attr,omitempty"`
+		Source string        `xml:"source,attr"`
+		Target string        `xml:"target,attr"`
+		Data   []graphMLData `xml:"data"`
+	}
+	graphMLData struct {
+		Key   string `xml:"key,attr"`
+		Value string `xml:",chardata"`
+	}
+)
+
+// WriteGraphML writes the graph in GraphML, with the node and edge fields as
+// data attributes.
+func WriteGraphML(w io.Writer, graph Graph) error {
+	document := graphML{
+		Xmlns: "http://graphml.graphdrawing.org/xmlns",
+		Keys: []graphMLKey{
+			{ID: "label", For: "node", Name: "label", Type: "string"},
+			{ID: "status", For: "node", Name: "status", Type: "string"},
+			{ID: "classification", For: "node", Name: "classification", Type: "string"},
+			{ID: "color", For: "node", Name: "color", Type: "string"},
+			{ID: "storyPoints", For: "node", Name: "storyPoints", Type: "double"},
+			{ID: "assignee", For: "node", Name: "assignee", Type: "string"},
+			{ID: "epic", For: "node", Name: "epic", Type: "string"},
+			{ID: "external", For: "node", Name: "external", Type: "boolean"},
+			{ID: "type", For: "edge", Name: "type", Type: "string"},
+		},
+		Graph: graphMLGraph{
+			ID:          graphName(graph),
+			EdgeDefault: "directed",
+		},
+	}
+
+	for _, node := range exportedNodes(graph) {
+		document.Graph.Nodes = append(document.Graph.Nodes, graphMLNode{
+			ID: node.ID,
+			Data: []graphMLData{
+				{Key: "label", Value: node.Label},
+				{Key: "status", Value: node.Status},
+				{Key: "classification", Value: node.Classification},
+				{Key: "color", Value: classificationColor(node.Classification)},
+				{Key: "storyPoints", Value: strconv.FormatFloat(node.StoryPoints, 'f', -1, 64)},
+				{Key: "assignee", Value: node.Assignee},
+				{Key: "epic", Value: node.Epic},
+				{Key: "external", Value: strconv.FormatBool(node.External)},
+			},
+		})
+	}
+
+	for _, edge := range graph.Edges {
+		document.Graph.Edges = append(document.Graph.Edges, graphMLEdge{
+			ID:     edge.ID,
+			Source: edge.From,
+			Target: edge.To,
+			Data:   []graphMLData{{Key: "type", Value: edge.Type}},
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(w, "\n")
+	return err
+}
@@ -0,0 +1,63 @@
+package jira
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata/export")
+
+// exportGraph has a summary full of characters each format has to escape,
+// and a link to an issue outside the epic that only a stub node describes
+func exportGraph() Graph {
+	return Graph{
+		Nodes: []GraphNode{
+			{ID: "ABC-1", Label: "Epic", Status: "Epic", Classification: "epic"},
+			{
+				ID:             "ABC-2",
+				Label:          "Say \"hi\" | <b>&amp;</b> [x] {y}\nC:\\temp",
+				Status:         "In Progress",
+				Classification: "indeterminate",
+				StoryPoints:    3,
+				Assignee:       "Ann",
+				Epic:           "ABC-1",
+			},
+			{ID: "ABC-3", Label: "Plain", Status: "To Do", Classification: "new", Epic: "ABC-1"},
+		},
+		Edges: []GraphEdge{
+			{From: "ABC-1", To: "ABC-2", Type: "epic link", Status: "Epic", Classification: "epic"},
+			{From: "ABC-1", To: "ABC-3", Type: "epic link", Status: "Epic", Classification: "epic"},
+			{ID: "100", From: "ABC-2", To: "ABC-3", Type: "Blocks", Status: "To Do", Classification: "new"},
+			{ID: "101", From: "ABC-3", To: "XYZ-9", Type: "Relates", Status: "Done", Classification: "done", StoryPoints: 2},
+		},
+	}
+}
+
+func TestWriteGraph(t *testing.T) {
+	for _, format := range GraphFormats {
+		t.Run(string(format), func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteGraph(&buf, exportGraph(), format); err != nil {
+				t.Fatalf("WriteGraph() error = %v", err)
+			}
+
+			golden := filepath.Join("testdata", "export", "graph"+format.Extension())
+			if *update {
+				if err := os.WriteFile(golden, buf.Bytes(), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			want, err := os.ReadFile(golden)
+			if err != nil {
+				t.Fatalf("reading the golden file: %v", err)
+			}
+			if got := buf.String(); got != string(want) {
+				t.Errorf("WriteGraph() =\n%s\nwant\n%s", got, want)
+			}
+		})
+	}
+}
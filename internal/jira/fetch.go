@@ -0,0 +1,177 @@
+package jira
+
+import (
+	"context"
+	"slices"
+
+	"github.com/Fuabioo/altalune/internal/model"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/sync/errgroup"
+)
+
+// keyPageSize is the page size used when only issue keys are listed. Jira
+// allows much bigger pages when no fields are requested.
+const keyPageSize = 5000
+
+// FetchEpicIssues fetches every issue of the epic, fetching the pages after
+// the first one with up to concurrency workers.
+//
+// When the search reports a total (legacy offset pagination) the remaining
+// offsets are known after the first page. Token pagination has no total and
+// can't jump ahead, so the remaining keys are listed in one cheap request and
+// their details are fetched in parallel batches instead.
+//
+// Issues are returned in search order regardless of which page finishes
+// first, and the first page to fail cancels the others.
+func FetchEpicIssues(ctx context.Context, source IssueSource, req ListEpicRequest, concurrency int) ([]*model.Ticket, error) {
+	first, err := source.ListEpicIssues(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Debug("Fetched first page of epic issues",
+		"epic", req.EpicID,
+		"issues", len(first.Issues),
+		"total", first.Total,
+		"isLast", first.IsLast,
+	)
+
+	pager := EpicPages(source, req)
+	pager.advance(first)
+	if !pager.More() {
+		return first.Issues, nil
+	}
+
+	var requests []ListEpicRequest
+	if first.NextPageToken == "" {
+		requests = offsetRequests(req, uint(first.StartAt+len(first.Issues)), first.Total)
+	} else {
+		keys, err := remainingKeys(ctx, source, req, first.Issues)
+		if err != nil {
+			return nil, err
+		}
+		requests = keyRequests(req, keys)
+	}
+
+	pages, err := fetchPages(ctx, source, requests, concurrency)
+	if err != nil {
+		return nil, err
+	}
+
+	issues := slices.Clone(first.Issues)
+	for _, page := range pages {
+		issues = append(issues, page...)
+	}
+
+	return issues, nil
+}
+
+func offsetRequests(req ListEpicRequest, startAt uint, total uint) []ListEpicRequest {
+	pageSize := max(req.PageSize, 1)
+
+	var requests []ListEpicRequest
+	for offset := startAt; offset < total; offset += pageSize {
+		page := req
+		page.StartAt = offset
+		requests = append(requests, page)
+	}
+
+	return requests
+}
+
+func keyRequests(req ListEpicRequest, keys []string) []ListEpicRequest {
+	pageSize := int(max(req.PageSize, 1))
+
+	var requests []ListEpicRequest
+	for chunk := range slices.Chunk(keys, pageSize) {
+		page := req
+		page.Keys = chunk
+		page.PageSize = uint(len(chunk))
+		requests = append(requests, page)
+	}
+
+	return requests
+}
+
+// remainingKeys lists the keys of the epic's issues that aren't in fetched.
+func remainingKeys(ctx context.Context, source IssueSource, req ListEpicRequest, fetched []*model.Ticket) ([]string, error) {
+	seen := make(map[string]bool, len(fetched))
+	for _, issue := range fetched {
+		seen[issue.Key] = true
+	}
+
+	// Only the keys are listed: neither fields nor expansions such as the
+	// changelog, which are fetched with the issues themselves.
+	req.Fields = []string{"id"}
+	req.Expand = nil
+	req.PageSize = keyPageSize
+	req.NextPageToken = ""
+	req.StartAt = 0
+
+	issues, err := ListAllEpicIssues(ctx, source, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var keys []string
+	for _, issue := range issues {
+		if !seen[issue.Key] {
+			keys = append(keys, issue.Key)
+		}
+	}
+
+	return keys, nil
+}
+
+// fetchPages runs the requests with a bounded worker pool and returns each
+// page's issues at the index of its request.
+func fetchPages(ctx context.Context, source IssueSource, requests []ListEpicRequest, concurrency int) ([][]*model.Ticket, error) {
+	pages := make([][]*model.Ticket, len(requests))
+
+	group, ctx := errgroup.WithContext(ctx)
+	group.SetLimit(max(concurrency, 1))
+
+	for i, req := range requests {
+		group.Go(func() error {
+			result, err := source.ListEpicIssues(ctx, req)
+			if err != nil {
+				return err
+			}
+
+			log.Debug("Fetched page of epic issues",
+				"epic", req.EpicID,
+				"page", i+1,
+				"issues", len(result.Issues),
+			)
+
+			pages[i] = orderByKeys(result.Issues, req.Keys)
+			return nil
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return nil, err
+	}
+
+	return pages, nil
+}
+
+// orderByKeys sorts issues in the order of keys, since Jira doesn't preserve
+// the order of a "key in (...)" clause.
+func orderByKeys(issues []*model.Ticket, keys []string) []*model.Ticket {
+	if len(keys) == 0 {
+		return issues
+	}
+
+	position := make(map[string]int, len(keys))
+	for i, key := range keys {
+		position[key] = i
+	}
+
+	slices.SortStableFunc(issues, func(a, b *model.Ticket) int {
+		return position[a.Key] - position[b.Key]
+	})
+
+	return issues
+}
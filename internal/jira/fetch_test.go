@@ -0,0 +1,136 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// recordingSource records the search requests sent to the fixtures
+type recordingSource struct {
+	*MemorySource
+
+	mu       sync.Mutex
+	requests []ListEpicRequest
+}
+
+func (r *recordingSource) ListEpicIssues(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error) {
+	r.mu.Lock()
+	r.requests = append(r.requests, req)
+	r.mu.Unlock()
+
+	return r.MemorySource.ListEpicIssues(ctx, req)
+}
+
+func TestFetchEpicIssuesListsKeysWithoutExpand(t *testing.T) {
+	source := &recordingSource{MemorySource: loadFixtures(t)}
+
+	issues, err := FetchEpicIssues(context.Background(), source, ListEpicRequest{
+		EpicID:   "ABC-1",
+		PageSize: 1,
+		Fields:   []string{"summary", "status"},
+		Expand:   []string{"changelog"},
+	}, 2)
+	if err != nil {
+		t.Fatalf("FetchEpicIssues() error = %v", err)
+	}
+	if got, want := issueKeys(issues), []string{"ABC-2", "ABC-3", "ABC-4", "ABC-5"}; !slices.Equal(got, want) {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+
+	var listings, chunks int
+	for _, req := range source.requests {
+		switch {
+		case slices.Equal(req.Fields, []string{"id"}):
+			listings++
+			if len(req.Expand) > 0 {
+				t.Errorf("key listing expands %v, want nothing", req.Expand)
+			}
+		case len(req.Keys) > 0:
+			chunks++
+			if !slices.Equal(req.Expand, []string{"changelog"}) {
+				t.Errorf("request for %v expands %v, want [changelog]", req.Keys, req.Expand)
+			}
+		}
+	}
+	if listings == 0 || chunks == 0 {
+		t.Errorf("got %d key listings and %d key requests, want both", listings, chunks)
+	}
+}
+
+// pageSource answers requests for the given keys with the handler instead of
+// the fixtures, once the first page was listed
+type pageSource struct {
+	*MemorySource
+	handle func(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error)
+}
+
+func (p *pageSource) ListEpicIssues(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error) {
+	if len(req.Keys) == 0 {
+		return p.MemorySource.ListEpicIssues(ctx, req)
+	}
+	return p.handle(ctx, req)
+}
+
+func TestFetchEpicIssuesKeepsSearchOrder(t *testing.T) {
+	fixtures := loadFixtures(t)
+
+	// Earlier pages finish last, and each page comes back in reverse
+	delays := map[string]time.Duration{"ABC-3": 30 * time.Millisecond, "ABC-4": 15 * time.Millisecond}
+	source := &pageSource{MemorySource: fixtures}
+	source.handle = func(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error) {
+		time.Sleep(delays[req.Keys[0]])
+
+		result, err := fixtures.ListEpicIssues(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		slices.Reverse(result.Issues)
+		return result, nil
+	}
+
+	for _, pageSize := range []uint{1, 2} {
+		issues, err := FetchEpicIssues(context.Background(), source, ListEpicRequest{EpicID: "ABC-1", PageSize: pageSize}, 3)
+		if err != nil {
+			t.Fatalf("FetchEpicIssues() with pages of %d error = %v", pageSize, err)
+		}
+		if got, want := issueKeys(issues), []string{"ABC-2", "ABC-3", "ABC-4", "ABC-5"}; !slices.Equal(got, want) {
+			t.Errorf("issues with pages of %d = %v, want %v", pageSize, got, want)
+		}
+	}
+}
+
+func TestFetchEpicIssuesCancelsOnError(t *testing.T) {
+	errPage := errors.New("page failed")
+
+	// ABC-3 fails right away, the other pages wait to be cancelled
+	var cancelled atomic.Int32
+	source := &pageSource{MemorySource: loadFixtures(t)}
+	source.handle = func(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error) {
+		if req.Keys[0] == "ABC-3" {
+			return nil, errPage
+		}
+
+		select {
+		case <-ctx.Done():
+			cancelled.Add(1)
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, errors.New("page was never cancelled")
+		}
+	}
+
+	_, err := FetchEpicIssues(context.Background(), source, ListEpicRequest{EpicID: "ABC-1", PageSize: 1}, 3)
+	if !errors.Is(err, errPage) {
+		t.Errorf("FetchEpicIssues() error = %v, want %v", err, errPage)
+	}
+	if got := cancelled.Load(); got != 2 {
+		t.Errorf("%d pages were cancelled, want the other 2", got)
+	}
+}
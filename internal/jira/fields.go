@@ -0,0 +1,41 @@
+package jira
+
+import (
+	"slices"
+	"strings"
+)
+
+// dashboardFields are the issue fields read by BuildGraph, CalculateStats,
+// ExtractAssignees and the issues table.
+var dashboardFields = []string{
+	"summary",
+	"status",
+	"statusCategory",
+	"issuetype",
+	"assignee",
+	"issuelinks",
+	"parent",
+	"created",
+	"updated",
+	"resolutiondate",
+}
+
+// DefaultFields returns the field projection the dashboard needs, including
+// the story point fields.
+func DefaultFields(points StoryPointFields) []string {
+	return MergeFields(dashboardFields, points)
+}
+
+// MergeFields appends the extra fields to the base projection, skipping
+// blanks and duplicates.
+func MergeFields(base []string, extra []string) []string {
+	fields := slices.Clone(base)
+	for _, field := range extra {
+		field = strings.TrimSpace(field)
+		if field == "" || slices.Contains(fields, field) {
+			continue
+		}
+		fields = append(fields, field)
+	}
+	return fields
+}
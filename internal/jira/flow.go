@@ -0,0 +1,175 @@
+package jira
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// IssueFlow is how long an issue took to get done. Lead time runs from
+// creation to done, cycle time from when work first started to done. Both
+// are in days and null until the issue is done.
+type IssueFlow struct {
+	Key           string     `json:"key"`
+	IssueType     string     `json:"issueType"`
+	Assignee      string     `json:"assignee"`
+	Created       time.Time  `json:"created"`
+	Started       *time.Time `json:"started"`
+	Done          *time.Time `json:"done"`
+	LeadTimeDays  *float64   `json:"leadTimeDays"`
+	CycleTimeDays *float64   `json:"cycleTimeDays"`
+}
+
+// Percentiles summarizes a distribution of durations in days
+type Percentiles struct {
+	Count int     `json:"count"`
+	P50   float64 `json:"p50"`
+	P85   float64 `json:"p85"`
+	P95   float64 `json:"p95"`
+}
+
+type FlowPercentiles struct {
+	LeadTime  Percentiles `json:"leadTime"`
+	CycleTime Percentiles `json:"cycleTime"`
+}
+
+// FlowStats are the lead and cycle time analytics of an epic, overall and
+// broken down by issue type and by assignee.
+type FlowStats struct {
+	FlowPercentiles
+	ByType     map[string]FlowPercentiles `json:"byType"`
+	ByAssignee map[string]FlowPercentiles `json:"byAssignee"`
+	Issues     []IssueFlow                `json:"issues"`
+}
+
+// unassigned is the assignee name issues without one are grouped under
+const unassigned = "Unassigned"
+
+// CalculateIssueFlow works out when the issue started and got done from its
+// status transitions. An issue counts as done from the last time it entered
+// a done status, so reopened work is measured until it's finally closed.
+func CalculateIssueFlow(issue *model.Ticket, categories StatusCategories) IssueFlow {
+	flow := IssueFlow{
+		Key:       issue.Key,
+		IssueType: issue.Fields.IssueType.Name,
+		Assignee:  issue.Fields.Assignee.DisplayName,
+		Created:   issue.Fields.Created.Time,
+	}
+	if flow.Assignee == "" {
+		flow.Assignee = unassigned
+	}
+
+	var started, done time.Time
+	for _, transition := range issue.StatusTransitions() {
+		switch categories.Of(transition.ToStatusID, transition.ToStatus) {
+		case "indeterminate":
+			if started.IsZero() {
+				started = transition.At
+			}
+		case "done":
+			done = transition.At
+		}
+	}
+
+	if !started.IsZero() {
+		flow.Started = &started
+	}
+
+	if issue.Fields.StatusCategory.Key != "done" || done.IsZero() {
+		return flow
+	}
+
+	flow.Done = &done
+
+	leadTime := days(done.Sub(flow.Created))
+	flow.LeadTimeDays = &leadTime
+
+	if flow.Started != nil && !started.After(done) {
+		cycleTime := days(done.Sub(started))
+		flow.CycleTimeDays = &cycleTime
+	}
+
+	return flow
+}
+
+// CalculateFlowStats computes the flow of every issue and the percentiles of
+// the ones that are done.
+func CalculateFlowStats(issues []*model.Ticket, categories StatusCategories) FlowStats {
+	stats := FlowStats{
+		ByType:     make(map[string]FlowPercentiles),
+		ByAssignee: make(map[string]FlowPercentiles),
+		Issues:     make([]IssueFlow, 0, len(issues)),
+	}
+
+	for _, issue := range issues {
+		stats.Issues = append(stats.Issues, CalculateIssueFlow(issue, categories))
+	}
+
+	stats.FlowPercentiles = flowPercentiles(stats.Issues)
+
+	byType := make(map[string][]IssueFlow)
+	byAssignee := make(map[string][]IssueFlow)
+	for _, flow := range stats.Issues {
+		byType[flow.IssueType] = append(byType[flow.IssueType], flow)
+		byAssignee[flow.Assignee] = append(byAssignee[flow.Assignee], flow)
+	}
+
+	for issueType, flows := range byType {
+		stats.ByType[issueType] = flowPercentiles(flows)
+	}
+	for assignee, flows := range byAssignee {
+		stats.ByAssignee[assignee] = flowPercentiles(flows)
+	}
+
+	return stats
+}
+
+func flowPercentiles(flows []IssueFlow) FlowPercentiles {
+	var leadTimes, cycleTimes []float64
+	for _, flow := range flows {
+		if flow.LeadTimeDays != nil {
+			leadTimes = append(leadTimes, *flow.LeadTimeDays)
+		}
+		if flow.CycleTimeDays != nil {
+			cycleTimes = append(cycleTimes, *flow.CycleTimeDays)
+		}
+	}
+
+	return FlowPercentiles{
+		LeadTime:  CalculatePercentiles(leadTimes),
+		CycleTime: CalculatePercentiles(cycleTimes),
+	}
+}
+
+// CalculatePercentiles returns the p50, p85 and p95 of values
+func CalculatePercentiles(values []float64) Percentiles {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	return Percentiles{
+		Count: len(sorted),
+		P50:   Percentile(sorted, 50),
+		P85:   Percentile(sorted, 85),
+		P95:   Percentile(sorted, 95),
+	}
+}
+
+// Percentile returns the p-th percentile of sorted values, interpolating
+// linearly between the closest ranks. It's 0 for no values.
+func Percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p / 100) * float64(len(sorted)-1)
+	lower := int(math.Floor(rank))
+	upper := int(math.Ceil(rank))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(rank-float64(lower))
+}
+
+func days(d time.Duration) float64 {
+	return d.Hours() / 24
+}
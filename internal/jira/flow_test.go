@@ -0,0 +1,94 @@
+package jira
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestCalculateIssueFlow(t *testing.T) {
+	issues, categories := loadBurnFixtures(t)
+
+	at := func(month time.Month, day int, hour int) *time.Time {
+		t := time.Date(2026, month, day, hour, 0, 0, 0, time.UTC)
+		return &t
+	}
+
+	tests := []struct {
+		name                string
+		key                 string
+		started, done       *time.Time
+		leadTime, cycleTime float64 // Days, -1 for none
+	}{
+		{"done after being in progress", "BRN-2", at(9, 30, 10), at(10, 2, 10), 4, 2},
+		{"reopened and done again", "BRN-8", at(9, 22, 10), at(10, 5, 10), 15, 13},
+		{"done without being in progress", "BRN-7", nil, at(10, 1, 12), 5 + 2.0/24, -1},
+		{"reopened and still open", "BRN-4", nil, nil, -1, -1},
+		{"still open", "BRN-3", nil, nil, -1, -1},
+	}
+
+	equalTime := func(a, b *time.Time) bool {
+		return a == nil && b == nil || a != nil && b != nil && a.Equal(*b)
+	}
+	equalDays := func(got *float64, want float64) bool {
+		if want < 0 {
+			return got == nil
+		}
+		return got != nil && math.Abs(*got-want) < 1e-9
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flow := CalculateIssueFlow(findIssue(t, issues, tt.key), categories)
+
+			if !equalTime(flow.Started, tt.started) {
+				t.Errorf("Started = %v, want %v", flow.Started, tt.started)
+			}
+			if !equalTime(flow.Done, tt.done) {
+				t.Errorf("Done = %v, want %v", flow.Done, tt.done)
+			}
+			if !equalDays(flow.LeadTimeDays, tt.leadTime) {
+				t.Errorf("LeadTimeDays = %v, want %v", flow.LeadTimeDays, tt.leadTime)
+			}
+			if !equalDays(flow.CycleTimeDays, tt.cycleTime) {
+				t.Errorf("CycleTimeDays = %v, want %v", flow.CycleTimeDays, tt.cycleTime)
+			}
+		})
+	}
+
+	stats := CalculateFlowStats(issues, categories)
+	if stats.LeadTime.Count != 3 || stats.CycleTime.Count != 2 {
+		t.Errorf("counts = %d lead and %d cycle times, want 3 and 2", stats.LeadTime.Count, stats.CycleTime.Count)
+	}
+	if got := stats.ByType["Bug"].CycleTime; got.Count != 1 || got.P50 != 13 {
+		t.Errorf("bug cycle times = %+v, want BRN-8's 13 days", got)
+	}
+	if got := stats.ByAssignee[unassigned].LeadTime.Count; got != 2 {
+		t.Errorf("unassigned lead times = %d, want 2", got)
+	}
+}
+
+func TestCalculatePercentiles(t *testing.T) {
+	tests := []struct {
+		name   string
+		values []float64
+		want   Percentiles
+	}{
+		{"none", nil, Percentiles{}},
+		{"one", []float64{4}, Percentiles{Count: 1, P50: 4, P85: 4, P95: 4}},
+		{"two", []float64{4, 2}, Percentiles{Count: 2, P50: 3, P85: 3.7, P95: 3.9}},
+		{"five", []float64{5, 1, 4, 2, 3}, Percentiles{Count: 5, P50: 3, P85: 4.4, P95: 4.8}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculatePercentiles(tt.values)
+			if got.Count != tt.want.Count ||
+				math.Abs(got.P50-tt.want.P50) > 1e-9 ||
+				math.Abs(got.P85-tt.want.P85) > 1e-9 ||
+				math.Abs(got.P95-tt.want.P95) > 1e-9 {
+				t.Errorf("CalculatePercentiles(%v) = %+v, want %+v", tt.values, got, tt.want)
+			}
+		})
+	}
+}
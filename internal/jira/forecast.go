@@ -0,0 +1,199 @@
+package jira
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"slices"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// ForecastUnit is what throughput and remaining work are measured in
+type ForecastUnit string
+
+const (
+	ForecastIssues ForecastUnit = "issues"
+	ForecastPoints ForecastUnit = "points"
+)
+
+// maxForecastWeeks caps a single trial, so that a throughput of mostly empty
+// weeks can't simulate forever.
+const maxForecastWeeks = 520
+
+// ErrNoThroughput is returned when nothing got done in the sampled weeks, so
+// there is nothing to forecast from.
+var ErrNoThroughput = errors.New("no throughput in the sampled weeks")
+
+// Completion is an issue getting done
+type Completion struct {
+	At     time.Time
+	Points float64
+}
+
+// Completions returns when each done issue got done: the last time it entered
+// a done status according to its changelog, or its resolution date when the
+// changelog isn't available.
+func Completions(issues []*model.Ticket, categories StatusCategories, points StoryPointFields) []Completion {
+	completions := make([]Completion, 0, len(issues))
+	for _, issue := range issues {
+		if issue.Fields.StatusCategory.Key != "done" {
+			continue
+		}
+
+		var at time.Time
+		if done := CalculateIssueFlow(issue, categories).Done; done != nil {
+			at = *done
+		} else if issue.Fields.ResolutionDate != nil {
+			at = issue.Fields.ResolutionDate.Time
+		}
+		if at.IsZero() {
+			continue
+		}
+
+		completions = append(completions, Completion{At: at, Points: points.Of(issue)})
+	}
+	return completions
+}
+
+// WeeklyThroughput buckets completions into the given number of weeks ending
+// at to, oldest first. Weeks where nothing got done count as zero.
+func WeeklyThroughput(completions []Completion, unit ForecastUnit, to time.Time, weeks int) []float64 {
+	throughput := make([]float64, weeks)
+	from := to.AddDate(0, 0, -7*weeks)
+
+	for _, completion := range completions {
+		if !completion.At.After(from) || completion.At.After(to) {
+			continue
+		}
+
+		week := int(completion.At.Sub(from) / (7 * 24 * time.Hour))
+		if week >= weeks {
+			week = weeks - 1
+		}
+
+		if unit == ForecastPoints {
+			throughput[week] += completion.Points
+		} else {
+			throughput[week]++
+		}
+	}
+
+	return throughput
+}
+
+// Remaining is the work left in the epic, that is every issue not done yet.
+// In points, unestimated issues count as zero and are counted apart, since
+// they make the forecast come out early.
+func Remaining(issues []*model.Ticket, unit ForecastUnit, points StoryPointFields) (remaining float64, unestimated int) {
+	for _, issue := range issues {
+		if issue.Fields.StatusCategory.Key == "done" {
+			continue
+		}
+		if unit != ForecastPoints {
+			remaining++
+			continue
+		}
+
+		storyPoints := points.Of(issue)
+		if storyPoints == 0 {
+			unestimated++
+		}
+		remaining += storyPoints
+	}
+	return remaining, unestimated
+}
+
+type ForecastOptions struct {
+	Trials int
+	Seed   uint64
+	Start  time.Time
+}
+
+// ForecastPoint is a completion date at a confidence level. A capped point
+// falls on a trial that never finished, so its date only means "not within
+// the cap".
+type ForecastPoint struct {
+	Weeks  int       `json:"weeks"`
+	Date   time.Time `json:"date"`
+	Capped bool      `json:"capped,omitempty"`
+}
+
+// Forecast is the outcome of a Monte Carlo simulation: with the given
+// confidence the remaining work is done within P50, P85 and P95. Capped is
+// how many trials were stopped at maxForecastWeeks without finishing, and
+// Unestimated how many remaining issues a points forecast counted as zero.
+type Forecast struct {
+	Unit        ForecastUnit  `json:"unit"`
+	Remaining   float64       `json:"remaining"`
+	Unestimated int           `json:"unestimated"`
+	Throughput  []float64     `json:"throughput"`
+	Trials      int           `json:"trials"`
+	Seed        uint64        `json:"seed"`
+	Start       time.Time     `json:"start"`
+	P50         ForecastPoint `json:"p50"`
+	P85         ForecastPoint `json:"p85"`
+	P95         ForecastPoint `json:"p95"`
+	Capped      int           `json:"capped"`
+}
+
+// RunForecast simulates finishing the remaining work by drawing a week of
+// throughput at random from the samples until it's done, once per trial.
+// The same seed always yields the same forecast.
+func RunForecast(samples []float64, remaining float64, unit ForecastUnit, opts ForecastOptions) (Forecast, error) {
+	if opts.Trials <= 0 {
+		return Forecast{}, fmt.Errorf("forecast needs at least one trial, got %d", opts.Trials)
+	}
+
+	forecast := Forecast{
+		Unit:       unit,
+		Remaining:  remaining,
+		Throughput: samples,
+		Trials:     opts.Trials,
+		Seed:       opts.Seed,
+		Start:      opts.Start,
+	}
+
+	if remaining > 0 && !slices.ContainsFunc(samples, func(sample float64) bool { return sample > 0 }) {
+		return forecast, ErrNoThroughput
+	}
+
+	rng := rand.New(rand.NewPCG(opts.Seed, opts.Seed))
+
+	// Capped trials are counted apart and sort last, past any trial that
+	// finished on the last week
+	outcomes := make([]int, opts.Trials)
+	for trial := range outcomes {
+		weeks := 0
+		left := remaining
+		for ; left > 0 && weeks < maxForecastWeeks; weeks++ {
+			left -= samples[rng.IntN(len(samples))]
+		}
+		if left > 0 {
+			forecast.Capped++
+		}
+		outcomes[trial] = weeks
+	}
+	slices.Sort(outcomes)
+
+	forecast.P50 = forecastPoint(outcomes, 50, forecast.Capped, opts.Start)
+	forecast.P85 = forecastPoint(outcomes, 85, forecast.Capped, opts.Start)
+	forecast.P95 = forecastPoint(outcomes, 95, forecast.Capped, opts.Start)
+
+	return forecast, nil
+}
+
+// forecastPoint picks the nearest-rank p-th percentile of the sorted trial
+// outcomes, the last capped of which are capped.
+func forecastPoint(outcomes []int, p float64, capped int, start time.Time) ForecastPoint {
+	rank := max(int(math.Ceil(p/100*float64(len(outcomes))))-1, 0)
+	weeks := outcomes[rank]
+
+	return ForecastPoint{
+		Weeks:  weeks,
+		Date:   start.AddDate(0, 0, 7*weeks),
+		Capped: rank >= len(outcomes)-capped,
+	}
+}
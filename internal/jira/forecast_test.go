@@ -0,0 +1,110 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRunForecastSeed(t *testing.T) {
+	samples := []float64{0, 1, 2, 3, 5, 1, 0, 4}
+	start := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+
+	run := func(seed uint64) Forecast {
+		t.Helper()
+		forecast, err := RunForecast(samples, 20, ForecastIssues, ForecastOptions{Trials: 50, Seed: seed, Start: start})
+		if err != nil {
+			t.Fatalf("RunForecast() error = %v", err)
+		}
+		return forecast
+	}
+
+	first, again := run(1), run(1)
+	if first.P50 != again.P50 || first.P85 != again.P85 || first.P95 != again.P95 {
+		t.Errorf("same seed gave %+v then %+v", first, again)
+	}
+	if !(first.P50.Weeks <= first.P85.Weeks && first.P85.Weeks <= first.P95.Weeks) {
+		t.Errorf("percentiles aren't ordered: %d, %d, %d", first.P50.Weeks, first.P85.Weeks, first.P95.Weeks)
+	}
+	if want := start.AddDate(0, 0, 7*first.P50.Weeks); !first.P50.Date.Equal(want) {
+		t.Errorf("P50 date = %s, want %s", first.P50.Date, want)
+	}
+
+	other := run(2)
+	if first.P50 == other.P50 && first.P85 == other.P85 && first.P95 == other.P95 {
+		t.Errorf("seeds 1 and 2 gave the same percentiles %+v", first)
+	}
+}
+
+func TestRunForecastEdgeCases(t *testing.T) {
+	start := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	opts := ForecastOptions{Trials: 100, Seed: 1, Start: start}
+
+	t.Run("zero throughput", func(t *testing.T) {
+		_, err := RunForecast([]float64{0, 0, 0}, 5, ForecastIssues, opts)
+		if !errors.Is(err, ErrNoThroughput) {
+			t.Errorf("RunForecast() error = %v, want ErrNoThroughput", err)
+		}
+	})
+
+	t.Run("zero remaining work", func(t *testing.T) {
+		for _, samples := range [][]float64{{1, 2}, {0, 0}} {
+			forecast, err := RunForecast(samples, 0, ForecastPoints, opts)
+			if err != nil {
+				t.Fatalf("RunForecast(%v) error = %v", samples, err)
+			}
+			want := ForecastPoint{Weeks: 0, Date: start}
+			if forecast.P50 != want || forecast.P85 != want || forecast.P95 != want {
+				t.Errorf("RunForecast(%v) = %+v, want every percentile at the start", samples, forecast)
+			}
+		}
+	})
+
+	t.Run("capped trials", func(t *testing.T) {
+		// A thousandth of the work a week never finishes within the cap
+		forecast, err := RunForecast([]float64{0.001}, 1, ForecastPoints, opts)
+		if err != nil {
+			t.Fatalf("RunForecast() error = %v", err)
+		}
+		if forecast.Capped != opts.Trials {
+			t.Errorf("Capped = %d, want all %d trials", forecast.Capped, opts.Trials)
+		}
+		for _, point := range []ForecastPoint{forecast.P50, forecast.P85, forecast.P95} {
+			if !point.Capped || point.Weeks != maxForecastWeeks {
+				t.Errorf("point = %+v, want capped at %d weeks", point, maxForecastWeeks)
+			}
+		}
+
+		forecast, err = RunForecast([]float64{1, 2}, 5, ForecastPoints, opts)
+		if err != nil {
+			t.Fatalf("RunForecast() error = %v", err)
+		}
+		if forecast.Capped != 0 || forecast.P95.Capped {
+			t.Errorf("Capped = %d, P95 = %+v, want nothing capped", forecast.Capped, forecast.P95)
+		}
+	})
+
+	t.Run("no trials", func(t *testing.T) {
+		if _, err := RunForecast([]float64{1}, 5, ForecastIssues, ForecastOptions{}); err == nil {
+			t.Error("RunForecast() without trials succeeded, want an error")
+		}
+	})
+}
+
+func TestRemaining(t *testing.T) {
+	source := loadFixtures(t)
+	result, err := source.ListEpicIssues(context.Background(), ListEpicRequest{EpicID: "ABC-1"})
+	if err != nil {
+		t.Fatalf("ListEpicIssues() error = %v", err)
+	}
+	points := StoryPointFields{"customfield_10016"}
+
+	// ABC-3, ABC-4 and ABC-5 aren't done, and ABC-4 has no estimate
+	if remaining, unestimated := Remaining(result.Issues, ForecastIssues, points); remaining != 3 || unestimated != 0 {
+		t.Errorf("Remaining() in issues = %v, %d, want 3, 0", remaining, unestimated)
+	}
+	if remaining, unestimated := Remaining(result.Issues, ForecastPoints, points); remaining != 7 || unestimated != 1 {
+		t.Errorf("Remaining() in points = %v, %d, want 7, 1", remaining, unestimated)
+	}
+}
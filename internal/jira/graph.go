@@ -1,6 +1,11 @@
 package jira
 
 import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
 	"github.com/Fuabioo/altalune/internal/model"
 )
 
@@ -17,42 +22,64 @@ type GraphNode struct {
 	StoryPoints    float64 `json:"storyPoints"`    // Story points
 	Assignee       string  `json:"assignee"`       // Assignee display name
 	AssigneeID     string  `json:"assigneeId"`     // Assignee account ID
+	Epic           string  `json:"epic,omitempty"` // Parent epic key, when known
+	External       bool    `json:"external"`       // Outside the epic, reached through links
 }
 
+// GraphEdge is an epic link or an issue link. Issue links are read from one
+// of their ends and describe the issue at the other end, the linked issue.
 type GraphEdge struct {
+	ID             string  `json:"id,omitempty"`   // Issue link ID, empty for epic links
 	From           string  `json:"from"`           // Source ticket key
 	To             string  `json:"to"`             // Destination ticket key
-	Type           string  `json:"type"`           // Optional: e.g., "blocks", "relates to"
-	Status         string  `json:"status"`         // Status
+	Type           string  `json:"type"`           // e.g. "epic link", "Blocks", "Relates"
+	Status         string  `json:"status"`         // Status of the linked issue
 	Classification string  `json:"classification"` // Status classification (new, indeterminate, done)
-	StoryPoints    float64 `json:"storyPoints"`    // Story points of the target issue
-	Assignee       string  `json:"assignee"`       // Assignee display name of the target issue
-	AssigneeID     string  `json:"assigneeId"`     // Assignee account ID of the target issue
+	StoryPoints    float64 `json:"storyPoints"`    // Story points of the linked issue
+	Assignee       string  `json:"assignee"`       // Assignee display name of the linked issue
+	AssigneeID     string  `json:"assigneeId"`     // Assignee account ID of the linked issue
 }
 
-func BuildGraph(issues []*model.Ticket, epicKey string) Graph {
+// BuildGraph links the epic to each of its issues and the issues to each
+// other. Links seen from both of their ends are only included once, and
+// blocking links always point from the blocker to the blocked issue,
+// whichever way Jira names them. Nodes and edges are sorted by issue key, so
+// the same issues always give the same graph.
+//
+// Linked issues from outside the epic, see ExpandLinkedIssues, are added as
+// external nodes tagged with their own epic, along with their links to
+// other nodes of the graph. Their links to issues that weren't fetched, past
+// the last hop, are left out.
+func BuildGraph(issues []*model.Ticket, epicKey string, points StoryPointFields, linked []*model.Ticket) Graph {
 	nodeMap := make(map[string]GraphNode)
 	var edges []GraphEdge
 
+	byKey := func(a, b *model.Ticket) int {
+		return CompareKeys(a.Key, b.Key)
+	}
+	issues = slices.SortedFunc(slices.Values(issues), byKey)
+	linked = slices.SortedFunc(slices.Values(linked), byKey)
+
 	// Build nodes from all issues
 	for _, issue := range issues {
-		storyPoints := getStoryPoints(issue)
-		assigneeName := ""
-		assigneeID := ""
-		if issue.Fields.Assignee.DisplayName != "" {
-			assigneeName = issue.Fields.Assignee.DisplayName
-			assigneeID = issue.Fields.Assignee.AccountID
+		node := issueNode(issue, points)
+		if issue.Key != epicKey {
+			node.Epic = epicKey
+		}
+		nodeMap[issue.Key] = node
+	}
+
+	for _, issue := range linked {
+		if _, exists := nodeMap[issue.Key]; exists {
+			continue
 		}
 
-		nodeMap[issue.Key] = GraphNode{
-			ID:             issue.Key,
-			Label:          issue.Fields.Summary,
-			Status:         issue.Fields.Status.Name,
-			Classification: issue.Fields.StatusCategory.Key,
-			StoryPoints:    storyPoints,
-			Assignee:       assigneeName,
-			AssigneeID:     assigneeID,
+		node := issueNode(issue, points)
+		node.External = true
+		if issue.Fields.Parent != nil {
+			node.Epic = issue.Fields.Parent.Key
 		}
+		nodeMap[issue.Key] = node
 	}
 
 	// Add the epic as a node (if not already in issues)
@@ -71,78 +98,71 @@ func BuildGraph(issues []*model.Ticket, epicKey string) Graph {
 	// Add default edges from epic to each ticket
 	for _, issue := range issues {
 		if issue.Key != epicKey {
-			storyPoints := getStoryPoints(issue)
-			assigneeName := ""
-			assigneeID := ""
-			if issue.Fields.Assignee.DisplayName != "" {
-				assigneeName = issue.Fields.Assignee.DisplayName
-				assigneeID = issue.Fields.Assignee.AccountID
-			}
-
-			edges = append(edges, GraphEdge{
-				From:           epicKey,
-				To:             issue.Key,
-				Type:           "epic link",
-				Status:         "Epic",
-				Classification: "epic",
-				StoryPoints:    storyPoints,
-				Assignee:       assigneeName,
-				AssigneeID:     assigneeID,
-			})
+			edge := linkedEdge(epicKey, issue.Key, "epic link", issue, points)
+			edge.Status = "Epic"
+			edge.Classification = "epic"
+			edges = append(edges, edge)
 		}
+	}
 
-		// Include issue links
+	// Include issue links, once per link even when both ends are in the epic
+	seen := make(map[string]bool)
+	var links []GraphEdge
+	for i, issue := range slices.Concat(issues, linked) {
+		external := i >= len(issues)
 		for _, link := range issue.Fields.IssueLinks {
-			// Outward
-			if link.OutwardIssue.Key != "" {
-				outwardStoryPoints := getStoryPoints(&link.OutwardIssue)
-				outwardAssigneeName := ""
-				outwardAssigneeID := ""
-				if link.OutwardIssue.Fields.Assignee.DisplayName != "" {
-					outwardAssigneeName = link.OutwardIssue.Fields.Assignee.DisplayName
-					outwardAssigneeID = link.OutwardIssue.Fields.Assignee.AccountID
-				}
-
-				edges = append(edges, GraphEdge{
-					From:           issue.Key,
-					To:             link.OutwardIssue.Key,
-					Type:           link.Type.Name,
-					Status:         link.OutwardIssue.Fields.Status.Name,
-					Classification: link.OutwardIssue.Fields.StatusCategory.Key,
-					StoryPoints:    outwardStoryPoints,
-					Assignee:       outwardAssigneeName,
-					AssigneeID:     outwardAssigneeID,
-				})
+			var edge GraphEdge
+			switch {
+			case link.OutwardIssue.Key != "":
+				edge = linkedEdge(issue.Key, link.OutwardIssue.Key, link.Type.Name, &link.OutwardIssue, points)
+			case link.InwardIssue.Key != "":
+				edge = linkedEdge(link.InwardIssue.Key, issue.Key, link.Type.Name, &link.InwardIssue, points)
+			default:
+				continue
 			}
-			// Inward
-			if link.InwardIssue.Key != "" {
-				inwardStoryPoints := getStoryPoints(&link.InwardIssue)
-				inwardAssigneeName := ""
-				inwardAssigneeID := ""
-				if link.InwardIssue.Fields.Assignee.DisplayName != "" {
-					inwardAssigneeName = link.InwardIssue.Fields.Assignee.DisplayName
-					inwardAssigneeID = link.InwardIssue.Fields.Assignee.AccountID
+
+			edge.ID = link.ID
+			edge.From, edge.To, edge.Type = normalizeLink(link.Type, edge.From, edge.To)
+
+			if external {
+				_, fromKnown := nodeMap[edge.From]
+				_, toKnown := nodeMap[edge.To]
+				if !fromKnown || !toKnown {
+					continue
 				}
+			}
 
-				edges = append(edges, GraphEdge{
-					From:           link.InwardIssue.Key,
-					To:             issue.Key,
-					Type:           link.Type.Name,
-					Status:         link.InwardIssue.Fields.Status.Name,
-					Classification: link.InwardIssue.Fields.StatusCategory.Key,
-					StoryPoints:    inwardStoryPoints,
-					Assignee:       inwardAssigneeName,
-					AssigneeID:     inwardAssigneeID,
-				})
+			id := edge.ID
+			if id == "" {
+				id = edge.From + " " + edge.Type + " " + edge.To
 			}
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			links = append(links, edge)
 		}
 	}
 
+	slices.SortStableFunc(links, func(a, b GraphEdge) int {
+		return cmp.Or(
+			CompareKeys(a.From, b.From),
+			CompareKeys(a.To, b.To),
+			strings.Compare(a.Type, b.Type),
+			strings.Compare(a.ID, b.ID),
+		)
+	})
+	edges = append(edges, links...)
+
 	// Build final node list
 	nodes := make([]GraphNode, 0, len(nodeMap))
 	for _, node := range nodeMap {
 		nodes = append(nodes, node)
 	}
+	slices.SortFunc(nodes, func(a, b GraphNode) int {
+		return CompareKeys(a.ID, b.ID)
+	})
 
 	return Graph{
 		Nodes: nodes,
@@ -150,23 +170,125 @@ func BuildGraph(issues []*model.Ticket, epicKey string) Graph {
 	}
 }
 
-// getStoryPoints extracts story points from common custom fields
-// Jira stores story points in custom fields with different IDs depending on the configuration:
+func issueNode(issue *model.Ticket, points StoryPointFields) GraphNode {
+	assigneeName := ""
+	assigneeID := ""
+	if issue.Fields.Assignee.DisplayName != "" {
+		assigneeName = issue.Fields.Assignee.DisplayName
+		assigneeID = issue.Fields.Assignee.ID()
+	}
+
+	return GraphNode{
+		ID:             issue.Key,
+		Label:          issue.Fields.Summary,
+		Status:         issue.Fields.Status.Name,
+		Classification: issue.Fields.StatusCategory.Key,
+		StoryPoints:    points.Of(issue),
+		Assignee:       assigneeName,
+		AssigneeID:     assigneeID,
+	}
+}
+
+// linkedEdge builds an edge describing the linked issue
+func linkedEdge(from string, to string, linkType string, linked *model.Ticket, points StoryPointFields) GraphEdge {
+	assigneeName := ""
+	assigneeID := ""
+	if linked.Fields.Assignee.DisplayName != "" {
+		assigneeName = linked.Fields.Assignee.DisplayName
+		assigneeID = linked.Fields.Assignee.ID()
+	}
+
+	return GraphEdge{
+		From:           from,
+		To:             to,
+		Type:           linkType,
+		Status:         linked.Fields.Status.Name,
+		Classification: linked.Fields.StatusCategory.Key,
+		StoryPoints:    points.Of(linked),
+		Assignee:       assigneeName,
+		AssigneeID:     assigneeID,
+	}
+}
+
+// blockedPhrases are outward link descriptions meaning the outward issue
+// blocks the one the link is on, the reverse of Jira's own Blocks type.
+var blockedPhrases = []string{"is blocked by", "depends on", "is dependent on", "requires"}
+
+// normalizeLink names every blocking link type "Blocks", flipping the ones
+// that point from the blocked issue to its blocker. An edge from -> to reads
+// "from <outward> to" in Jira's terms.
+func normalizeLink(linkType model.LinkType, from string, to string) (string, string, string) {
+	outward := strings.ToLower(strings.TrimSpace(linkType.Outward))
+
+	if outward == "blocks" || (outward == "" && strings.EqualFold(linkType.Name, "blocks")) {
+		return from, to, "Blocks"
+	}
+
+	if slices.Contains(blockedPhrases, outward) {
+		return to, from, "Blocks"
+	}
+
+	return from, to, linkType.Name
+}
+
+// CompareKeys orders issue keys by project, then numerically by issue
+// number, so ABC-9 comes before ABC-10.
+func CompareKeys(a string, b string) int {
+	aProject, aNumber, aOK := splitKey(a)
+	bProject, bNumber, bOK := splitKey(b)
+	if !aOK || !bOK {
+		return strings.Compare(a, b)
+	}
+
+	return cmp.Or(strings.Compare(aProject, bProject), cmp.Compare(aNumber, bNumber))
+}
+
+func splitKey(key string) (string, int, bool) {
+	i := strings.LastIndex(key, "-")
+	if i < 0 {
+		return "", 0, false
+	}
+
+	number, err := strconv.Atoi(key[i+1:])
+	if err != nil {
+		return "", 0, false
+	}
+
+	return key[:i], number, true
+}
+
+// StoryPointFields lists the custom fields that may hold story points, in
+// order of preference. Jira stores story points in custom fields whose IDs
+// depend on the configuration:
 // - customfield_10016: Most common in Jira Cloud instances
 // - customfield_10002: Common in Jira Server instances
 // - customfield_10004: Alternative configuration
 // - customfield_10008: Another alternative
-// This function tries each field in order and returns the first non-zero value found
-func getStoryPoints(issue *model.Ticket) float64 {
-	// Common story points custom field IDs
-	storyPointFields := []string{
+type StoryPointFields []string
+
+// DefaultStoryPointFields returns the usual candidates for the given REST API
+// version, with the most likely one for that flavour of Jira first.
+func DefaultStoryPointFields(apiVersion string) StoryPointFields {
+	if apiVersion == "2" {
+		return StoryPointFields{
+			"customfield_10002", // Common in Jira Server
+			"customfield_10004", // Another common one
+			"customfield_10008", // Alternative
+			"customfield_10016", // Common in Jira Cloud
+		}
+	}
+
+	return StoryPointFields{
 		"customfield_10016", // Common in Jira Cloud
 		"customfield_10002", // Common in Jira Server
 		"customfield_10004", // Another common one
 		"customfield_10008", // Alternative
 	}
+}
 
-	for _, fieldID := range storyPointFields {
+// Of tries each field in order and returns the first non-zero value found
+func (f StoryPointFields) Of(issue *model.Ticket) float64 {
+	for _, fieldID := range f {
 		if points := issue.Fields.GetCustomFieldAsFloat(fieldID); points > 0 {
 			return points
 		}
@@ -174,3 +296,12 @@ func getStoryPoints(issue *model.Ticket) float64 {
 
 	return 0
 }
+
+// NodesByID indexes the graph's nodes by issue key
+func (g Graph) NodesByID() map[string]GraphNode {
+	nodes := make(map[string]GraphNode, len(g.Nodes))
+	for _, node := range g.Nodes {
+		nodes[node.ID] = node
+	}
+	return nodes
+}
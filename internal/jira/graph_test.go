@@ -0,0 +1,137 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+func TestBuildGraphLinkedIssues(t *testing.T) {
+	source := loadFixtures(t)
+	result, err := source.ListEpicIssues(context.Background(), ListEpicRequest{EpicID: "ABC-1"})
+	if err != nil {
+		t.Fatalf("ListEpicIssues() error = %v", err)
+	}
+
+	// XYZ-9 was reached through its link to ABC-4, QQQ-5 is one hop further
+	var linked model.Ticket
+	err = json.Unmarshal([]byte(`{
+		"key": "XYZ-9",
+		"fields": {
+			"summary": "Nine",
+			"status": {"name": "To Do"},
+			"statusCategory": {"key": "new"},
+			"parent": {"key": "XYZ-1"},
+			"issuelinks": [
+				{"id": "102", "type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"}, "outwardIssue": {"key": "ABC-4"}},
+				{"id": "103", "type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"}, "outwardIssue": {"key": "QQQ-5"}}
+			]
+		}
+	}`), &linked)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	graph := BuildGraph(result.Issues, "ABC-1", StoryPointFields{"customfield_10016"}, []*model.Ticket{&linked})
+
+	nodes := graph.NodesByID()
+	if node, ok := nodes["XYZ-9"]; !ok || !node.External || node.Epic != "XYZ-1" {
+		t.Errorf("XYZ-9 node = %+v, %v, want an external node of XYZ-1", node, ok)
+	}
+	if _, ok := nodes["QQQ-5"]; ok {
+		t.Error("graph has a QQQ-5 node, want none past the last hop")
+	}
+
+	links := make(map[string]GraphEdge)
+	for _, edge := range graph.Edges {
+		if edge.ID != "" {
+			links[edge.ID] = edge
+		}
+	}
+	if edge, ok := links["102"]; !ok || edge.From != "XYZ-9" || edge.To != "ABC-4" {
+		t.Errorf("link 102 = %+v, %v, want XYZ-9 blocks ABC-4", edge, ok)
+	}
+	if edge, ok := links["103"]; ok {
+		t.Errorf("graph has link 103 %+v to an issue that wasn't fetched", edge)
+	}
+}
+
+func TestBuildGraphNormalizesLinks(t *testing.T) {
+	var issues []*model.Ticket
+	err := json.Unmarshal([]byte(`[
+		{
+			"key": "ABC-10",
+			"fields": {
+				"summary": "Ten",
+				"status": {"name": "In Progress"},
+				"statusCategory": {"key": "indeterminate"},
+				"customfield_10016": 5,
+				"issuelinks": [
+					{"id": "200", "type": {"name": "Dependency", "inward": "is depended on by", "outward": "depends on"}, "outwardIssue": {"key": "ABC-9", "fields": {"status": {"name": "To Do"}, "statusCategory": {"key": "new"}, "customfield_10016": 2}}}
+				]
+			}
+		},
+		{
+			"key": "ABC-2",
+			"fields": {
+				"summary": "Two",
+				"status": {"name": "Done"},
+				"statusCategory": {"key": "done"},
+				"customfield_10016": 3,
+				"issuelinks": [
+					{"id": "202", "type": {"name": "Relates", "inward": "relates to", "outward": "relates to"}, "outwardIssue": {"key": "ABC-10", "fields": {"status": {"name": "In Progress"}, "statusCategory": {"key": "indeterminate"}, "customfield_10016": 5}}},
+					{"id": "201", "type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"}, "outwardIssue": {"key": "ABC-9", "fields": {"status": {"name": "To Do"}, "statusCategory": {"key": "new"}, "customfield_10016": 2}}}
+				]
+			}
+		},
+		{
+			"key": "ABC-9",
+			"fields": {
+				"summary": "Nine",
+				"status": {"name": "To Do"},
+				"statusCategory": {"key": "new"},
+				"customfield_10016": 2,
+				"issuelinks": [
+					{"id": "200", "type": {"name": "Dependency", "inward": "is depended on by", "outward": "depends on"}, "inwardIssue": {"key": "ABC-10", "fields": {"status": {"name": "In Progress"}, "statusCategory": {"key": "indeterminate"}, "customfield_10016": 5}}},
+					{"id": "201", "type": {"name": "Blocks", "inward": "is blocked by", "outward": "blocks"}, "inwardIssue": {"key": "ABC-2", "fields": {"status": {"name": "Done"}, "statusCategory": {"key": "done"}, "customfield_10016": 3}}}
+				]
+			}
+		}
+	]`), &issues)
+	if err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+
+	epicLink := func(to string, points float64) GraphEdge {
+		return GraphEdge{From: "ABC-1", To: to, Type: "epic link", Status: "Epic", Classification: "epic", StoryPoints: points}
+	}
+	want := Graph{
+		Nodes: []GraphNode{
+			{ID: "ABC-1", Label: "Epic", Status: "Epic", Classification: "epic"},
+			{ID: "ABC-2", Label: "Two", Status: "Done", Classification: "done", StoryPoints: 3, Epic: "ABC-1"},
+			{ID: "ABC-9", Label: "Nine", Status: "To Do", Classification: "new", StoryPoints: 2, Epic: "ABC-1"},
+			{ID: "ABC-10", Label: "Ten", Status: "In Progress", Classification: "indeterminate", StoryPoints: 5, Epic: "ABC-1"},
+		},
+		Edges: []GraphEdge{
+			epicLink("ABC-2", 3),
+			epicLink("ABC-9", 2),
+			epicLink("ABC-10", 5),
+			// Seen from both ends, described from ABC-2, the first of them
+			{ID: "201", From: "ABC-2", To: "ABC-9", Type: "Blocks", Status: "To Do", Classification: "new", StoryPoints: 2},
+			{ID: "202", From: "ABC-2", To: "ABC-10", Type: "Relates", Status: "In Progress", Classification: "indeterminate", StoryPoints: 5},
+			// "ABC-10 depends on ABC-9", flipped into the blocking direction
+			{ID: "200", From: "ABC-9", To: "ABC-10", Type: "Blocks", Status: "In Progress", Classification: "indeterminate", StoryPoints: 5},
+		},
+	}
+
+	points := StoryPointFields{"customfield_10016"}
+	shuffled := [][]*model.Ticket{issues, {issues[2], issues[0], issues[1]}, {issues[1], issues[2], issues[0]}}
+	for _, input := range shuffled {
+		if got := BuildGraph(input, "ABC-1", points, nil); !reflect.DeepEqual(got, want) {
+			t.Errorf("BuildGraph(%v) =\n%+v\nwant\n%+v", issueKeys(input), got, want)
+		}
+	}
+}
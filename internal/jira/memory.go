@@ -0,0 +1,216 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// MemorySource is an IssueSource that serves epics from memory instead of a
+// live Jira instance.
+type MemorySource struct {
+	epics    map[string][]*model.Ticket
+	fields   []model.FieldMeta
+	statuses []model.Status
+}
+
+// The optional fixtures holding /field and /status responses.
+const (
+	fieldsFixture   = "fields.json"
+	statusesFixture = "statuses.json"
+)
+
+func NewMemorySource(epics map[string][]*model.Ticket, fields []model.FieldMeta) *MemorySource {
+	if epics == nil {
+		epics = make(map[string][]*model.Ticket)
+	}
+
+	return &MemorySource{
+		epics:  epics,
+		fields: fields,
+	}
+}
+
+// LoadMemorySource reads every "<EPIC-KEY>.json" file at the root of fsys.
+// Each file holds a Jira search response, so real API payloads can be saved
+// and replayed as fixtures. The optional fields.json and statuses.json hold
+// /field and /status responses.
+func LoadMemorySource(fsys fs.FS) (*MemorySource, error) {
+	files, err := fs.Glob(fsys, "*.json")
+	if err != nil {
+		return nil, fmt.Errorf("error listing fixtures: %w", err)
+	}
+
+	var fields []model.FieldMeta
+	var statuses []model.Status
+	epics := make(map[string][]*model.Ticket, len(files))
+	for _, file := range files {
+		data, err := fs.ReadFile(fsys, file)
+		if err != nil {
+			return nil, fmt.Errorf("error reading fixture %s: %w", file, err)
+		}
+
+		switch path.Base(file) {
+		case fieldsFixture:
+			if err := json.Unmarshal(data, &fields); err != nil {
+				return nil, fmt.Errorf("error unmarshalling fixture %s: %w", file, err)
+			}
+			continue
+		case statusesFixture:
+			if err := json.Unmarshal(data, &statuses); err != nil {
+				return nil, fmt.Errorf("error unmarshalling fixture %s: %w", file, err)
+			}
+			continue
+		}
+
+		var result model.SearchResult
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("error unmarshalling fixture %s: %w", file, err)
+		}
+
+		epicKey := strings.TrimSuffix(path.Base(file), ".json")
+		epics[epicKey] = result.Issues
+	}
+
+	source := NewMemorySource(epics, fields)
+	source.statuses = statuses
+
+	return source, nil
+}
+
+func (m *MemorySource) Ping(ctx context.Context) error {
+	return ctx.Err()
+}
+
+func (m *MemorySource) ListFields(ctx context.Context) ([]model.FieldMeta, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return m.fields, nil
+}
+
+// ListStatuses returns the statuses fixture, or the statuses the fixture
+// issues are currently in when there is none.
+func (m *MemorySource) ListStatuses(ctx context.Context) ([]model.Status, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if m.statuses != nil {
+		return m.statuses, nil
+	}
+
+	seen := make(map[string]bool)
+	var statuses []model.Status
+	for _, issues := range m.epics {
+		for _, issue := range issues {
+			status := issue.Fields.Status
+			if seen[status.Name] {
+				continue
+			}
+			seen[status.Name] = true
+
+			if status.StatusCategory == nil {
+				category := issue.Fields.StatusCategory
+				status.StatusCategory = &category
+			}
+			statuses = append(statuses, status)
+		}
+	}
+
+	return statuses, nil
+}
+
+func (m *MemorySource) ListIssueChangelog(ctx context.Context, key string) ([]model.ChangelogHistory, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	issues := m.find([]string{key})
+	if len(issues) == 0 {
+		return nil, NotFoundError(key)
+	}
+
+	if issues[0].Changelog == nil {
+		return nil, nil
+	}
+	return issues[0].Changelog.Histories, nil
+}
+
+// ListEpicIssues pages through the epic's fixture using the same token
+// semantics as /search/jql. The token is the offset of the next page.
+func (m *MemorySource) ListEpicIssues(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if req.JQL != "" {
+		return nil, &APIError{
+			StatusCode:    http.StatusBadRequest,
+			ErrorMessages: []string{"JQL queries can't be answered from fixtures"},
+		}
+	}
+
+	issues, ok := m.epics[req.EpicID]
+	if len(req.Keys) > 0 {
+		issues, ok = m.find(req.Keys), true
+	}
+	if !ok {
+		return nil, NotFoundError(req.EpicID)
+	}
+
+	start := int(req.StartAt)
+	if req.NextPageToken != "" {
+		offset, err := strconv.Atoi(req.NextPageToken)
+		if err != nil {
+			return nil, fmt.Errorf("invalid page token %q: %w", req.NextPageToken, err)
+		}
+		start = offset
+	}
+	start = min(start, len(issues))
+
+	end := len(issues)
+	if req.PageSize > 0 {
+		end = min(start+int(req.PageSize), len(issues))
+	}
+
+	result := &model.SearchResult{
+		StartAt:    start,
+		MaxResults: int(req.PageSize),
+		Total:      uint(len(issues)),
+		IsLast:     end == len(issues),
+		Issues:     issues[start:end],
+	}
+	if !result.IsLast {
+		result.NextPageToken = strconv.Itoa(end)
+	}
+
+	return result, nil
+}
+
+// find returns the known issues among keys, in the order they are given.
+func (m *MemorySource) find(keys []string) []*model.Ticket {
+	byKey := make(map[string]*model.Ticket)
+	for _, issues := range m.epics {
+		for _, issue := range issues {
+			byKey[issue.Key] = issue
+		}
+	}
+
+	var found []*model.Ticket
+	for _, key := range keys {
+		if issue, ok := byKey[key]; ok {
+			found = append(found, issue)
+		}
+	}
+
+	return found
+}
@@ -0,0 +1,137 @@
+package jira
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+	"testing/fstest"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// loadFixtures loads the ABC-1 epic from testdata
+func loadFixtures(t *testing.T) *MemorySource {
+	t.Helper()
+
+	source, err := LoadMemorySource(os.DirFS("testdata/ABC-1"))
+	if err != nil {
+		t.Fatalf("LoadMemorySource() error = %v", err)
+	}
+	return source
+}
+
+func issueKeys(issues []*model.Ticket) []string {
+	keys := make([]string, 0, len(issues))
+	for _, issue := range issues {
+		keys = append(keys, issue.Key)
+	}
+	return keys
+}
+
+func TestLoadMemorySource(t *testing.T) {
+	ctx := context.Background()
+	source := loadFixtures(t)
+	points := StoryPointFields{"customfield_10016"}
+
+	result, err := source.ListEpicIssues(ctx, ListEpicRequest{EpicID: "ABC-1"})
+	if err != nil {
+		t.Fatalf("ListEpicIssues() error = %v", err)
+	}
+	if got, want := issueKeys(result.Issues), []string{"ABC-2", "ABC-3", "ABC-4", "ABC-5"}; !slices.Equal(got, want) {
+		t.Errorf("issues = %v, want %v", got, want)
+	}
+
+	fields, err := source.ListFields(ctx)
+	if err != nil {
+		t.Fatalf("ListFields() error = %v", err)
+	}
+	if field, ok := FindStoryPointField(fields); !ok || field.ID != "customfield_10016" {
+		t.Errorf("FindStoryPointField() = %v, %v, want customfield_10016", field.ID, ok)
+	}
+
+	statuses, err := source.ListStatuses(ctx)
+	if err != nil {
+		t.Fatalf("ListStatuses() error = %v", err)
+	}
+	if got := NewStatusCategories(statuses).Of("3", "In Progress"); got != "indeterminate" {
+		t.Errorf("category of In Progress = %q, want indeterminate", got)
+	}
+
+	// Saving the issues as a fixture again loads the same epic
+	data, err := json.Marshal(model.SearchResult{Issues: result.Issues})
+	if err != nil {
+		t.Fatalf("json.Marshal() error = %v", err)
+	}
+	reloaded, err := LoadMemorySource(fstest.MapFS{"ABC-1.json": {Data: data}})
+	if err != nil {
+		t.Fatalf("LoadMemorySource() of the saved fixture error = %v", err)
+	}
+	again, err := reloaded.ListEpicIssues(ctx, ListEpicRequest{EpicID: "ABC-1"})
+	if err != nil {
+		t.Fatalf("ListEpicIssues() error = %v", err)
+	}
+	if got, want := issueKeys(again.Issues), issueKeys(result.Issues); !slices.Equal(got, want) {
+		t.Errorf("reloaded issues = %v, want %v", got, want)
+	}
+	if got, want := CalculateStats(again.Issues, points), CalculateStats(result.Issues, points); got != want {
+		t.Errorf("reloaded stats = %+v, want %+v", got, want)
+	}
+
+	if _, err := source.ListEpicIssues(ctx, ListEpicRequest{EpicID: "NOPE-1"}); !isNotFound(err) {
+		t.Errorf("ListEpicIssues() of an unknown epic error = %v, want not found", err)
+	}
+}
+
+func TestMemorySourcePaging(t *testing.T) {
+	ctx := context.Background()
+	source := loadFixtures(t)
+
+	req := ListEpicRequest{EpicID: "ABC-1", PageSize: 3}
+	first, err := source.ListEpicIssues(ctx, req)
+	if err != nil {
+		t.Fatalf("ListEpicIssues() error = %v", err)
+	}
+	if got := issueKeys(first.Issues); !slices.Equal(got, []string{"ABC-2", "ABC-3", "ABC-4"}) || first.IsLast || first.NextPageToken != "3" {
+		t.Fatalf("first page = %v, isLast %v, token %q, want 3 issues and token \"3\"", got, first.IsLast, first.NextPageToken)
+	}
+
+	req.NextPageToken = first.NextPageToken
+	second, err := source.ListEpicIssues(ctx, req)
+	if err != nil {
+		t.Fatalf("ListEpicIssues() error = %v", err)
+	}
+	if got := issueKeys(second.Issues); !slices.Equal(got, []string{"ABC-5"}) || !second.IsLast || second.NextPageToken != "" {
+		t.Errorf("second page = %v, isLast %v, token %q, want the last issue", got, second.IsLast, second.NextPageToken)
+	}
+
+	all, err := ListAllEpicIssues(ctx, source, ListEpicRequest{EpicID: "ABC-1", PageSize: 1})
+	if err != nil {
+		t.Fatalf("ListAllEpicIssues() error = %v", err)
+	}
+	if got := len(all); got != 4 {
+		t.Errorf("ListAllEpicIssues() returned %d issues, want 4", got)
+	}
+
+	req.NextPageToken = "not-an-offset"
+	if _, err := source.ListEpicIssues(ctx, req); err == nil {
+		t.Error("ListEpicIssues() with an invalid token succeeded, want an error")
+	}
+}
+
+func TestMemorySourceRejectsJQL(t *testing.T) {
+	source := loadFixtures(t)
+
+	_, err := source.ListEpicIssues(context.Background(), ListEpicRequest{JQL: "project = ABC"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("ListEpicIssues() error = %v, want an *APIError", err)
+	}
+	if apiErr.StatusCode != http.StatusBadRequest || apiErr.Kind() != ErrorKindBadRequest {
+		t.Errorf("error = %d %s, want 400 %s", apiErr.StatusCode, apiErr.Kind(), ErrorKindBadRequest)
+	}
+}
@@ -0,0 +1,79 @@
+package jira
+
+import (
+	"context"
+	"errors"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+var ErrNoMorePages = errors.New("no more pages")
+
+// EpicPager walks every page of an epic's issues. It understands both the
+// offset pagination of the legacy search endpoint and the token pagination of
+// /search/jql, so callers don't need to care which one the source uses.
+type EpicPager struct {
+	source IssueSource
+	req    ListEpicRequest
+	done   bool
+}
+
+func EpicPages(source IssueSource, req ListEpicRequest) *EpicPager {
+	return &EpicPager{
+		source: source,
+		req:    req,
+	}
+}
+
+// More reports whether there are pages left to fetch.
+func (p *EpicPager) More() bool {
+	return !p.done
+}
+
+// Next fetches the next page and advances the pager.
+func (p *EpicPager) Next(ctx context.Context) (*model.SearchResult, error) {
+	if p.done {
+		return nil, ErrNoMorePages
+	}
+
+	result, err := p.source.ListEpicIssues(ctx, p.req)
+	if err != nil {
+		return nil, err
+	}
+
+	p.advance(result)
+
+	return result, nil
+}
+
+func (p *EpicPager) advance(result *model.SearchResult) {
+	fetched := uint(result.StartAt) + uint(len(result.Issues))
+
+	switch {
+	case result.NextPageToken != "":
+		p.req.NextPageToken = result.NextPageToken
+		p.req.StartAt = fetched
+	case result.IsLast || len(result.Issues) == 0:
+		p.done = true
+	case fetched < result.Total:
+		p.req.StartAt = fetched
+	default:
+		p.done = true
+	}
+}
+
+// ListAllEpicIssues fetches every page of the epic's issues.
+func ListAllEpicIssues(ctx context.Context, source IssueSource, req ListEpicRequest) ([]*model.Ticket, error) {
+	var issues []*model.Ticket
+
+	pager := EpicPages(source, req)
+	for pager.More() {
+		result, err := pager.Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+		issues = append(issues, result.Issues...)
+	}
+
+	return issues, nil
+}
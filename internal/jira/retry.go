@@ -0,0 +1,70 @@
+package jira
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/charmbracelet/log"
+	"resty.dev/v3"
+)
+
+// RetryPolicy controls how failed requests are retried. Requests are retried
+// on transport errors, 429 and 5xx responses, waiting an exponential backoff
+// with jitter between InitialBackoff and MaxBackoff, unless Jira sends a
+// Retry-After header, which always wins.
+type RetryPolicy struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts:    4,
+		InitialBackoff: 500 * time.Millisecond,
+		MaxBackoff:     10 * time.Second,
+	}
+}
+
+// RetryStats counts the retries performed by a client, by cause.
+type RetryStats struct {
+	Total       uint64 `json:"total"`
+	RateLimited uint64 `json:"rateLimited"`
+	ServerError uint64 `json:"serverError"`
+	Transport   uint64 `json:"transport"`
+}
+
+type retryCounters struct {
+	total       atomic.Uint64
+	rateLimited atomic.Uint64
+	serverError atomic.Uint64
+	transport   atomic.Uint64
+}
+
+func (r *retryCounters) hook(resp *resty.Response, err error) {
+	r.total.Add(1)
+
+	switch {
+	case err != nil:
+		r.transport.Add(1)
+		log.Warn("Retrying Jira request after transport error", "err", err)
+	case resp != nil && resp.StatusCode() == http.StatusTooManyRequests:
+		r.rateLimited.Add(1)
+		log.Warn("Retrying rate-limited Jira request",
+			"retryAfter", resp.Header().Get("Retry-After"),
+		)
+	case resp != nil:
+		r.serverError.Add(1)
+		log.Warn("Retrying Jira request", "status", resp.StatusCode())
+	}
+}
+
+func (r *retryCounters) snapshot() RetryStats {
+	return RetryStats{
+		Total:       r.total.Load(),
+		RateLimited: r.rateLimited.Load(),
+		ServerError: r.serverError.Load(),
+		Transport:   r.transport.Load(),
+	}
+}
@@ -0,0 +1,22 @@
+package jira
+
+import (
+	"context"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// IssueSource is everything Altalune needs from Jira. The HTTP client is the
+// production implementation, MemorySource serves fixtures.
+type IssueSource interface {
+	Ping(ctx context.Context) error
+	ListEpicIssues(ctx context.Context, req ListEpicRequest) (*model.SearchResult, error)
+	ListFields(ctx context.Context) ([]model.FieldMeta, error)
+	ListStatuses(ctx context.Context) ([]model.Status, error)
+	ListIssueChangelog(ctx context.Context, key string) ([]model.ChangelogHistory, error)
+}
+
+var (
+	_ IssueSource = (*Client)(nil)
+	_ IssueSource = (*MemorySource)(nil)
+)
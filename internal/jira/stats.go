@@ -1,6 +1,8 @@
 package jira
 
 import (
+	"math"
+
 	"github.com/Fuabioo/altalune/internal/model"
 )
 
@@ -11,13 +13,26 @@ type Assignee struct {
 	Active      bool   `json:"active"`
 }
 
+// EpicStats is the progress of an epic by issue count and, weighted by
+// story points, by points. Unestimated issues count as zero points. Issues
+// whose status category isn't one of new, indeterminate or done are counted
+// as Unknown rather than dropped, and are part of what remains to be done.
 type EpicStats struct {
 	Total       int     `json:"total"`
 	ToDo        int     `json:"toDo"`
 	InProgress  int     `json:"inProgress"`
 	Done        int     `json:"done"`
+	Unknown     int     `json:"unknown"`
 	Percentage  float64 `json:"percentage"`
 	ProgressPer float64 `json:"progressPer"`
+
+	TotalPoints       float64 `json:"totalPoints"`
+	DonePoints        float64 `json:"donePoints"`
+	InProgressPoints  float64 `json:"inProgressPoints"`
+	RemainingPoints   float64 `json:"remainingPoints"` // TotalPoints - DonePoints
+	PointsPercentage  float64 `json:"pointsPercentage"`
+	PointsProgressPer float64 `json:"pointsProgressPer"`
+	Unestimated       int     `json:"unestimated"` // Issues without story points
 }
 
 type StatusClassified struct {
@@ -27,33 +42,60 @@ type StatusClassified struct {
 type StatusCounts map[string]*StatusClassified
 type TypeCounts map[string]int
 
-func CalculateStats(issues []*model.Ticket) EpicStats {
+// CalculateStats counts the epic's issues by status category, and sums their
+// story points read from the same fields as BuildGraph. Every percentage is
+// between 0 and 100, and 0 when there is nothing to divide by.
+func CalculateStats(issues []*model.Ticket, points StoryPointFields) EpicStats {
 	stats := EpicStats{}
 
 	for _, issue := range issues {
+		if issue == nil {
+			continue
+		}
+
 		stats.Total++
 
+		storyPoints := points.Of(issue)
+		stats.TotalPoints += storyPoints
+		if storyPoints == 0 {
+			stats.Unestimated++
+		}
+
 		classification := issue.Fields.StatusCategory.Key
 		switch classification {
 		case "new":
 			stats.ToDo++
 		case "indeterminate":
 			stats.InProgress++
+			stats.InProgressPoints += storyPoints
 		case "done":
 			stats.Done++
+			stats.DonePoints += storyPoints
+		default:
+			stats.Unknown++
 		}
 	}
 
-	if stats.Total > 0 {
-		stats.Percentage = (float64(stats.Done) / float64(stats.Total)) * 100
-	}
+	stats.RemainingPoints = stats.TotalPoints - stats.DonePoints
 
-	totalWithoutCompleted := stats.Total - stats.Done
-	stats.ProgressPer = (float64(stats.InProgress) / float64(totalWithoutCompleted)) * 100
+	stats.Percentage = percent(float64(stats.Done), float64(stats.Total))
+	stats.ProgressPer = percent(float64(stats.InProgress), float64(stats.Total-stats.Done))
+	stats.PointsPercentage = percent(stats.DonePoints, stats.TotalPoints)
+	stats.PointsProgressPer = percent(stats.InProgressPoints, stats.RemainingPoints)
 
 	return stats
 }
 
+// percent returns part as a percentage of whole, clamped to [0, 100]. It's 0
+// when whole is zero or either value isn't finite, so it never yields the NaN
+// or Inf that encoding/json refuses to encode.
+func percent(part float64, whole float64) float64 {
+	if whole <= 0 || math.IsInf(whole, 0) || math.IsNaN(part) || math.IsInf(part, 0) {
+		return 0
+	}
+	return min(max(part/whole*100, 0), 100)
+}
+
 func CalculateStatusCounts(issues []*model.Ticket) StatusCounts {
 	counts := make(StatusCounts)
 
@@ -89,10 +131,10 @@ func ExtractAssignees(issues []*model.Ticket) []Assignee {
 	assigneeMap := make(map[string]Assignee)
 
 	for _, issue := range issues {
-		if issue.Fields.Assignee.AccountID != "" {
+		if assigneeID := issue.Fields.Assignee.ID(); assigneeID != "" {
 			avatarURL := getAvatarURL(issue.Fields.Assignee.AvatarURLs)
-			assigneeMap[issue.Fields.Assignee.AccountID] = Assignee{
-				AccountID:   issue.Fields.Assignee.AccountID,
+			assigneeMap[assigneeID] = Assignee{
+				AccountID:   assigneeID,
 				DisplayName: issue.Fields.Assignee.DisplayName,
 				AvatarURL:   avatarURL,
 				Active:      issue.Fields.Assignee.Active,
@@ -0,0 +1,143 @@
+package jira
+
+import (
+	"math"
+	"testing"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// ticket is an issue in the status category with the story points in
+// customfield_10016, unestimated when points is 0.
+func ticket(key string, category string, points float64) *model.Ticket {
+	issue := &model.Ticket{Key: key}
+	issue.Fields.StatusCategory.Key = category
+	if points > 0 {
+		issue.Fields.CustomFields = map[string]any{"customfield_10016": points}
+	}
+	return issue
+}
+
+// rounded rounds the percentages, so that stats compare regardless of
+// floating point error.
+func rounded(stats EpicStats) EpicStats {
+	for _, value := range []*float64{
+		&stats.Percentage,
+		&stats.ProgressPer,
+		&stats.PointsPercentage,
+		&stats.PointsProgressPer,
+	} {
+		*value = math.Round(*value*1e6) / 1e6
+	}
+	return stats
+}
+
+func TestCalculateStats(t *testing.T) {
+	points := StoryPointFields{"customfield_10016"}
+
+	tests := []struct {
+		name   string
+		issues []*model.Ticket
+		want   EpicStats
+	}{
+		{
+			name:   "empty epic",
+			issues: nil,
+			want:   EpicStats{},
+		},
+		{
+			name: "all done",
+			issues: []*model.Ticket{
+				ticket("ABC-1", "done", 3),
+				ticket("ABC-2", "done", 5),
+			},
+			want: EpicStats{
+				Total:            2,
+				Done:             2,
+				Percentage:       100,
+				TotalPoints:      8,
+				DonePoints:       8,
+				PointsPercentage: 100,
+			},
+		},
+		{
+			name: "mixed",
+			issues: []*model.Ticket{
+				ticket("ABC-1", "done", 2),
+				ticket("ABC-2", "indeterminate", 3),
+				ticket("ABC-3", "new", 5),
+				ticket("ABC-4", "new", 0),
+			},
+			want: EpicStats{
+				Total:             4,
+				ToDo:              2,
+				InProgress:        1,
+				Done:              1,
+				Percentage:        25,
+				ProgressPer:       100.0 / 3,
+				TotalPoints:       10,
+				DonePoints:        2,
+				InProgressPoints:  3,
+				RemainingPoints:   8,
+				PointsPercentage:  20,
+				PointsProgressPer: 37.5,
+				Unestimated:       1,
+			},
+		},
+		{
+			name: "unknown status category",
+			issues: []*model.Ticket{
+				ticket("ABC-1", "done", 1),
+				ticket("ABC-2", "undefined", 1),
+				ticket("ABC-3", "", 2),
+				nil,
+			},
+			want: EpicStats{
+				Total:            3,
+				Done:             1,
+				Unknown:          2,
+				Percentage:       100.0 / 3,
+				TotalPoints:      4,
+				DonePoints:       1,
+				RemainingPoints:  3,
+				PointsPercentage: 25,
+			},
+		},
+		{
+			name: "unestimated",
+			issues: []*model.Ticket{
+				ticket("ABC-1", "done", 0),
+				ticket("ABC-2", "indeterminate", 0),
+			},
+			want: EpicStats{
+				Total:       2,
+				InProgress:  1,
+				Done:        1,
+				Percentage:  50,
+				ProgressPer: 100,
+				Unestimated: 2,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := CalculateStats(tt.issues, points)
+
+			for name, value := range map[string]float64{
+				"Percentage":        got.Percentage,
+				"ProgressPer":       got.ProgressPer,
+				"PointsPercentage":  got.PointsPercentage,
+				"PointsProgressPer": got.PointsProgressPer,
+			} {
+				if math.IsNaN(value) || math.IsInf(value, 0) {
+					t.Errorf("%s = %v, want a finite number", name, value)
+				}
+			}
+
+			if rounded(got) != rounded(tt.want) {
+				t.Errorf("CalculateStats() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,151 @@
+package jira
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+
+	"github.com/charmbracelet/log"
+	"golang.org/x/sync/singleflight"
+)
+
+// storyPointFieldNames are the names Jira gives the story point field on
+// company-managed and team-managed projects respectively.
+var storyPointFieldNames = []string{
+	"Story Points",
+	"Story point estimate",
+}
+
+// FindStoryPointField looks up the story point field by name, preferring
+// numeric fields. The names default to the ones Jira ships with.
+func FindStoryPointField(fields []model.FieldMeta, names ...string) (model.FieldMeta, bool) {
+	if len(names) == 0 {
+		names = storyPointFieldNames
+	}
+
+	var found *model.FieldMeta
+	for _, name := range names {
+		for i, field := range fields {
+			if !strings.EqualFold(strings.TrimSpace(field.Name), name) {
+				continue
+			}
+			if field.Schema != nil && field.Schema.Type == "number" {
+				return field, true
+			}
+			if found == nil {
+				found = &fields[i]
+			}
+		}
+	}
+
+	if found == nil {
+		return model.FieldMeta{}, false
+	}
+
+	return *found, true
+}
+
+// storyPointRetryDelay is how long the fallback candidates are used after
+// failing to list the fields, before trying again.
+const storyPointRetryDelay = time.Minute
+
+// StoryPointResolver works out which field holds story points the first time
+// it's asked and caches the answer. The override from Config.StoryPointField
+// can be either a field ID or a field name.
+type StoryPointResolver struct {
+	source   IssueSource
+	override string
+	fallback StoryPointFields
+	now      func() time.Time
+
+	mu       sync.Mutex
+	resolved StoryPointFields
+	retryAt  time.Time
+	flight   singleflight.Group
+}
+
+func NewStoryPointResolver(source IssueSource, cfg Config) *StoryPointResolver {
+	cfg = cfg.WithDefaults()
+
+	r := &StoryPointResolver{
+		source:   source,
+		override: strings.TrimSpace(cfg.StoryPointField),
+		fallback: cfg.StoryPointFields,
+		now:      time.Now,
+	}
+	if strings.HasPrefix(r.override, "customfield_") {
+		r.resolved = StoryPointFields{r.override}
+	}
+
+	return r
+}
+
+// Fields returns the story point field to read. Concurrent callers share a
+// single lookup of the fields. When discovery fails the fallback candidates
+// are returned, and keep being returned for storyPointRetryDelay before
+// discovery is tried again.
+func (r *StoryPointResolver) Fields(ctx context.Context) StoryPointFields {
+	r.mu.Lock()
+	resolved, retryAt := r.resolved, r.retryAt
+	r.mu.Unlock()
+
+	if resolved != nil {
+		return resolved
+	}
+	if r.now().Before(retryAt) {
+		return r.fallback
+	}
+
+	ch := r.flight.DoChan("fields", func() (any, error) {
+		return r.resolve(context.WithoutCancel(ctx)), nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return r.fallback
+	case res := <-ch:
+		return res.Val.(StoryPointFields)
+	}
+}
+
+func (r *StoryPointResolver) resolve(ctx context.Context) StoryPointFields {
+	fields, err := r.source.ListFields(ctx)
+	if err != nil {
+		log.Warn("Could not list Jira fields, guessing the story point field",
+			"err", err,
+			"candidates", r.fallback,
+			"retryIn", storyPointRetryDelay,
+		)
+
+		r.mu.Lock()
+		r.retryAt = r.now().Add(storyPointRetryDelay)
+		r.mu.Unlock()
+
+		return r.fallback
+	}
+
+	var names []string
+	if r.override != "" {
+		names = []string{r.override}
+	}
+
+	resolved := r.fallback
+	if field, ok := FindStoryPointField(fields, names...); ok {
+		log.Debug("Resolved story point field", "id", field.ID, "name", field.Name)
+		resolved = StoryPointFields{field.ID}
+	} else {
+		log.Warn("No story point field found, guessing",
+			"names", names,
+			"candidates", r.fallback,
+		)
+	}
+
+	r.mu.Lock()
+	r.resolved = resolved
+	r.mu.Unlock()
+
+	return resolved
+}
@@ -0,0 +1,100 @@
+package jira
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// fieldsSource counts the field listings sent to the fixtures, failing them
+// while fail is set and holding them until release is closed
+type fieldsSource struct {
+	*MemorySource
+
+	fail    atomic.Bool
+	calls   atomic.Int32
+	release chan struct{}
+}
+
+func (f *fieldsSource) ListFields(ctx context.Context) ([]model.FieldMeta, error) {
+	f.calls.Add(1)
+	<-f.release
+	if f.fail.Load() {
+		return nil, errors.New("jira is down")
+	}
+	return f.MemorySource.ListFields(ctx)
+}
+
+func TestStoryPointResolver(t *testing.T) {
+	ctx := context.Background()
+	fallback := StoryPointFields{"customfield_10016", "customfield_10026"}
+
+	source := &fieldsSource{MemorySource: loadFixtures(t), release: make(chan struct{})}
+	source.fail.Store(true)
+
+	now := time.Date(2026, 10, 5, 0, 0, 0, 0, time.UTC)
+	resolver := NewStoryPointResolver(source, Config{StoryPointFields: fallback})
+	resolver.now = func() time.Time { return now }
+
+	// Concurrent callers share a single failed lookup
+	var wg sync.WaitGroup
+	results := make([]StoryPointFields, 5)
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = resolver.Fields(ctx)
+		}()
+	}
+	for source.calls.Load() == 0 {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(source.release)
+	wg.Wait()
+
+	for _, got := range results {
+		if !slices.Equal(got, fallback) {
+			t.Errorf("Fields() = %v, want the fallback %v", got, fallback)
+		}
+	}
+	if got := source.calls.Load(); got != 1 {
+		t.Errorf("listed the fields %d times, want once", got)
+	}
+
+	// The fallback is cached until the retry delay has passed
+	source.fail.Store(false)
+	now = now.Add(storyPointRetryDelay / 2)
+	if got := resolver.Fields(ctx); !slices.Equal(got, fallback) || source.calls.Load() != 1 {
+		t.Errorf("Fields() during the retry delay = %v after %d listings, want the fallback after 1", got, source.calls.Load())
+	}
+
+	now = now.Add(storyPointRetryDelay)
+	want := StoryPointFields{"customfield_10016"}
+	for range 2 {
+		if got := resolver.Fields(ctx); !slices.Equal(got, want) {
+			t.Errorf("Fields() after the retry delay = %v, want %v", got, want)
+		}
+	}
+	if got := source.calls.Load(); got != 2 {
+		t.Errorf("listed the fields %d times, want twice", got)
+	}
+}
+
+func TestStoryPointResolverFieldID(t *testing.T) {
+	source := &fieldsSource{MemorySource: loadFixtures(t)}
+	resolver := NewStoryPointResolver(source, Config{StoryPointField: "customfield_12345"})
+
+	if got, want := resolver.Fields(context.Background()), (StoryPointFields{"customfield_12345"}); !slices.Equal(got, want) {
+		t.Errorf("Fields() = %v, want %v", got, want)
+	}
+	if got := source.calls.Load(); got != 0 {
+		t.Errorf("listed the fields %d times, want none for a field ID", got)
+	}
+}
@@ -0,0 +1,88 @@
+package model
+
+import (
+	"slices"
+	"time"
+)
+
+// Changelog is the history of changes made to an issue
+type Changelog struct {
+	StartAt    int                `json:"startAt"`
+	MaxResults int                `json:"maxResults"`
+	Total      int                `json:"total"`
+	Histories  []ChangelogHistory `json:"histories"`
+}
+
+// ChangelogHistory is a set of changes made together
+type ChangelogHistory struct {
+	ID      string          `json:"id"`
+	Author  User            `json:"author"`
+	Created JiraTime        `json:"created"`
+	Items   []ChangelogItem `json:"items"`
+}
+
+// ChangelogItem is the change of a single field. From and To hold IDs, such
+// as status IDs, while FromString and ToString hold display values.
+type ChangelogItem struct {
+	Field      string `json:"field"`
+	FieldType  string `json:"fieldtype"`
+	FieldID    string `json:"fieldId"`
+	From       string `json:"from"`
+	FromString string `json:"fromString"`
+	To         string `json:"to"`
+	ToString   string `json:"toString"`
+}
+
+// ChangelogPage is a page of the /issue/{key}/changelog endpoint
+type ChangelogPage struct {
+	StartAt    int                `json:"startAt"`
+	MaxResults int                `json:"maxResults"`
+	Total      int                `json:"total"`
+	IsLast     bool               `json:"isLast"`
+	Values     []ChangelogHistory `json:"values"`
+}
+
+// StatusTransition is a change of status of an issue
+type StatusTransition struct {
+	At           time.Time `json:"at"`
+	FromStatusID string    `json:"fromStatusId"`
+	FromStatus   string    `json:"fromStatus"`
+	ToStatusID   string    `json:"toStatusId"`
+	ToStatus     string    `json:"toStatus"`
+}
+
+// IsComplete reports whether every history of the changelog was returned.
+// Searches only embed the most recent histories of each issue.
+func (c *Changelog) IsComplete() bool {
+	return c == nil || len(c.Histories) >= c.Total
+}
+
+// StatusTransitions returns the status changes of the ticket, oldest first.
+// It's empty unless the ticket was fetched with its changelog.
+func (t *Ticket) StatusTransitions() []StatusTransition {
+	if t.Changelog == nil {
+		return nil
+	}
+
+	var transitions []StatusTransition
+	for _, history := range t.Changelog.Histories {
+		for _, item := range history.Items {
+			if item.Field != "status" {
+				continue
+			}
+			transitions = append(transitions, StatusTransition{
+				At:           history.Created.Time,
+				FromStatusID: item.From,
+				FromStatus:   item.FromString,
+				ToStatusID:   item.To,
+				ToStatus:     item.ToString,
+			})
+		}
+	}
+
+	slices.SortStableFunc(transitions, func(a, b StatusTransition) int {
+		return a.At.Compare(b.At)
+	})
+
+	return transitions
+}
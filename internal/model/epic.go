@@ -6,19 +6,25 @@ import (
 	"time"
 )
 
+// SearchResult is a single page of a Jira issue search. The legacy /search
+// endpoint reports StartAt and Total, while /search/jql only reports
+// NextPageToken and IsLast.
 type SearchResult struct {
-	StartAt    int       `json:"startAt"`
-	MaxResults int       `json:"maxResults"`
-	Total      uint      `json:"total"`
-	Issues     []*Ticket `json:"issues"`
+	StartAt       int       `json:"startAt"`
+	MaxResults    int       `json:"maxResults"`
+	Total         uint      `json:"total"`
+	NextPageToken string    `json:"nextPageToken"`
+	IsLast        bool      `json:"isLast"`
+	Issues        []*Ticket `json:"issues"`
 }
 
 type Ticket struct {
-	Expand string `json:"expand"`
-	ID     string `json:"id"`
-	Self   string `json:"self"`
-	Key    string `json:"key"`
-	Fields Fields `json:"fields"`
+	Expand    string     `json:"expand"`
+	ID        string     `json:"id"`
+	Self      string     `json:"self"`
+	Key       string     `json:"key"`
+	Fields    Fields     `json:"fields"`
+	Changelog *Changelog `json:"changelog,omitempty"`
 }
 
 type IssueLink struct {
@@ -64,7 +70,7 @@ type Fields struct {
 	TimeSpent                any                `json:"timespent"`
 	Project                  Project            `json:"project"`
 	AggregateTimeSpent       any                `json:"aggregatetimespent"`
-	ResolutionDate           any                `json:"resolutiondate"`
+	ResolutionDate           *JiraTime          `json:"resolutiondate"`
 	WorkRatio                int                `json:"workratio"`
 	Watches                  *Watches           `json:"watches"`
 	Created                  JiraTime           `json:"created"`
@@ -118,10 +124,13 @@ type Status struct {
 	StatusCategory *StatusCategory `json:"statusCategory"`
 }
 
-// User represents a JIRA user
+// User represents a JIRA user. Jira Cloud identifies users by AccountID,
+// while Data Center and Server use Key and Name instead.
 type User struct {
 	Self         string            `json:"self"`
 	AccountID    string            `json:"accountId"`
+	Key          string            `json:"key,omitempty"`
+	Name         string            `json:"name,omitempty"`
 	EmailAddress string            `json:"emailAddress"`
 	AvatarURLs   map[string]string `json:"avatarUrls"`
 	DisplayName  string            `json:"displayName"`
@@ -130,6 +139,18 @@ type User struct {
 	AccountType  string            `json:"accountType"`
 }
 
+// ID returns the identifier of the user on either Jira flavour
+func (u User) ID() string {
+	switch {
+	case u.AccountID != "":
+		return u.AccountID
+	case u.Key != "":
+		return u.Key
+	default:
+		return u.Name
+	}
+}
+
 // Progress represents progress information
 type Progress struct {
 	Progress int `json:"progress"`
@@ -193,6 +214,38 @@ type AtlassianDocumentMark struct {
 	Type string `json:"type"`
 }
 
+// UnmarshalJSON implements custom JSON unmarshalling for AtlassianDocument.
+// API v2 returns descriptions as plain text instead of ADF, so strings are
+// converted into a document with one paragraph per line.
+func (d *AtlassianDocument) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*d = AtlassianDocument{
+			Type:    "doc",
+			Version: 1,
+		}
+		for _, line := range strings.Split(text, "\n") {
+			paragraph := AtlassianDocumentNode{Type: "paragraph"}
+			if line = strings.TrimRight(line, "\r"); line != "" {
+				paragraph.Content = []AtlassianDocumentNode{{Type: "text", Text: line}}
+			}
+			d.Content = append(d.Content, paragraph)
+		}
+		return nil
+	}
+
+	type AtlassianDocumentAlias AtlassianDocument
+
+	var alias AtlassianDocumentAlias
+	if err := json.Unmarshal(data, &alias); err != nil {
+		return err
+	}
+
+	*d = AtlassianDocument(alias)
+
+	return nil
+}
+
 // JiraTime wraps time.Time to handle JIRA's date format
 type JiraTime struct {
 	time.Time
@@ -275,6 +328,32 @@ func (f *Fields) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// MarshalJSON implements custom JSON marshalling for Fields, writing custom
+// fields back under their original IDs
+func (f Fields) MarshalJSON() ([]byte, error) {
+	type FieldsAlias Fields
+
+	data, err := json.Marshal(FieldsAlias(f))
+	if err != nil || len(f.CustomFields) == 0 {
+		return data, err
+	}
+
+	var fieldMap map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fieldMap); err != nil {
+		return nil, err
+	}
+
+	for key, value := range f.CustomFields {
+		raw, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+		fieldMap[key] = raw
+	}
+
+	return json.Marshal(fieldMap)
+}
+
 // GetCustomField returns a custom field value by field ID
 func (f *Fields) GetCustomField(fieldID string) (any, bool) {
 	if f.CustomFields == nil {
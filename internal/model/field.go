@@ -0,0 +1,20 @@
+package model
+
+// FieldMeta describes a field as listed by Jira's /field endpoint
+type FieldMeta struct {
+	ID          string       `json:"id"`
+	Key         string       `json:"key"`
+	Name        string       `json:"name"`
+	Custom      bool         `json:"custom"`
+	Navigable   bool         `json:"navigable"`
+	Searchable  bool         `json:"searchable"`
+	ClauseNames []string     `json:"clauseNames"`
+	Schema      *FieldSchema `json:"schema"`
+}
+
+// FieldSchema describes the type of a field
+type FieldSchema struct {
+	Type     string `json:"type"`
+	Custom   string `json:"custom"`
+	CustomID int    `json:"customId"`
+}
@@ -0,0 +1,219 @@
+package report
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/table"
+)
+
+// Colors of the status categories, matching the web interface and the
+// exported graphs.
+var (
+	colorNew           = lipgloss.Color("#cad3f5")
+	colorIndeterminate = lipgloss.Color("#8aadf4")
+	colorDone          = lipgloss.Color("#a6da95")
+	colorBlocked       = lipgloss.Color("#ed8796")
+	colorMuted         = lipgloss.Color("#6e738d")
+	colorAccent        = lipgloss.Color("#c6a0f6")
+)
+
+var (
+	titleStyle   = lipgloss.NewStyle().Bold(true).Foreground(colorAccent)
+	sectionStyle = lipgloss.NewStyle().Bold(true)
+	mutedStyle   = lipgloss.NewStyle().Foreground(colorMuted)
+	headerStyle  = lipgloss.NewStyle().Bold(true).Padding(0, 1)
+	cellStyle    = lipgloss.NewStyle().Padding(0, 1)
+	numberStyle  = cellStyle.Align(lipgloss.Right)
+	borderStyle  = lipgloss.NewStyle().Foreground(colorMuted)
+)
+
+// Render draws the report for a terminal width columns wide
+func Render(report Report, width int) string {
+	barWidth := min(max(width-45, 10), 40)
+	summaryWidth := max(width-40, 20)
+
+	title := titleStyle.Render(report.Epic)
+	if report.Summary != "" {
+		title += " " + lipgloss.NewStyle().Bold(true).Render(report.Summary)
+	}
+
+	header := title + "\n" +
+		mutedStyle.Render("Fetched "+report.FetchedAt.Local().Format("2006-01-02 15:04"))
+
+	sections := []string{
+		header,
+		section("Progress", Progress(report, barWidth)),
+		lipgloss.JoinHorizontal(lipgloss.Top,
+			section("Statuses", statusTable(report)),
+			"  ",
+			section("Types", typeTable(report)),
+		),
+		section("Assignees", assigneeTable(report)),
+		section("Blocked", blockedTable(report, summaryWidth)),
+	}
+
+	return strings.Join(sections, "\n\n") + "\n"
+}
+
+func section(title string, body string) string {
+	return sectionStyle.Render(title) + "\n" + body
+}
+
+// Progress draws a bar by issue count and, when anything is estimated, one
+// by story points, each width cells wide.
+func Progress(report Report, width int) string {
+	stats := report.Stats
+
+	lines := []string{fmt.Sprintf("%-7s %s %3.0f%%  %s",
+		"Issues",
+		bar(float64(stats.Done), float64(stats.InProgress), float64(stats.Total), width),
+		stats.Percentage,
+		mutedStyle.Render(fmt.Sprintf("%d of %d done, %d in progress", stats.Done, stats.Total, stats.InProgress)),
+	)}
+
+	if stats.TotalPoints > 0 {
+		detail := fmt.Sprintf("%s of %s done, %s in progress",
+			formatPoints(stats.DonePoints),
+			formatPoints(stats.TotalPoints)+" pts",
+			formatPoints(stats.InProgressPoints),
+		)
+		if stats.Unestimated > 0 {
+			detail += fmt.Sprintf(", %d unestimated", stats.Unestimated)
+		}
+
+		lines = append(lines, fmt.Sprintf("%-7s %s %3.0f%%  %s",
+			"Points",
+			bar(stats.DonePoints, stats.InProgressPoints, stats.TotalPoints, width),
+			stats.PointsPercentage,
+			mutedStyle.Render(detail),
+		))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// bar fills width cells in proportion to done and in progress out of total,
+// shaded differently so they read apart without colors too.
+func bar(done float64, inProgress float64, total float64, width int) string {
+	if total <= 0 {
+		return mutedStyle.Render(strings.Repeat("░", width))
+	}
+
+	doneCells := min(int(math.Round(done/total*float64(width))), width)
+	progressCells := min(int(math.Round((done+inProgress)/total*float64(width))), width) - doneCells
+
+	return lipgloss.NewStyle().Foreground(colorDone).Render(strings.Repeat("█", doneCells)) +
+		lipgloss.NewStyle().Foreground(colorIndeterminate).Render(strings.Repeat("▓", progressCells)) +
+		mutedStyle.Render(strings.Repeat("░", width-doneCells-progressCells))
+}
+
+func formatPoints(points float64) string {
+	return strconv.FormatFloat(points, 'f', -1, 64)
+}
+
+// StatusStyle colors text by status classification
+func StatusStyle(classification string) lipgloss.Style {
+	return lipgloss.NewStyle().Foreground(classificationColor(classification))
+}
+
+func classificationColor(classification string) lipgloss.Color {
+	switch classification {
+	case "new":
+		return colorNew
+	case "indeterminate":
+		return colorIndeterminate
+	case "done":
+		return colorDone
+	default:
+		return colorMuted
+	}
+}
+
+// newTable is a table with the report's borders, whose columns from
+// numeric on are right aligned.
+func newTable(numeric int, headers ...string) *table.Table {
+	return table.New().
+		Border(lipgloss.RoundedBorder()).
+		BorderStyle(borderStyle).
+		Headers(headers...).
+		StyleFunc(func(row, col int) lipgloss.Style {
+			switch {
+			case row == table.HeaderRow:
+				return headerStyle
+			case col >= numeric:
+				return numberStyle
+			default:
+				return cellStyle
+			}
+		})
+}
+
+func statusTable(report Report) string {
+	t := newTable(1, "Status", "Issues")
+	for _, status := range report.StatusCounts {
+		t.Row(
+			StatusStyle(status.Classification).Render(status.Status),
+			strconv.FormatUint(uint64(status.Count), 10),
+		)
+	}
+	return t.Render()
+}
+
+func typeTable(report Report) string {
+	t := newTable(1, "Type", "Issues")
+	for _, issueType := range report.TypeCounts {
+		t.Row(issueType.Type, strconv.Itoa(issueType.Count))
+	}
+	return t.Render()
+}
+
+func assigneeTable(report Report) string {
+	if len(report.Assignees) == 0 {
+		return mutedStyle.Render("No issues")
+	}
+
+	t := newTable(1, "Assignee", "To Do", "In Progress", "Done", "Points")
+	for _, assignee := range report.Assignees {
+		name := assignee.DisplayName
+		if assignee.AccountID == "" {
+			name = mutedStyle.Render(name)
+		}
+		t.Row(
+			name,
+			strconv.Itoa(assignee.ToDo),
+			strconv.Itoa(assignee.InProgress),
+			strconv.Itoa(assignee.Done),
+			formatPoints(assignee.StoryPoints),
+		)
+	}
+	return t.Render()
+}
+
+func blockedTable(report Report, summaryWidth int) string {
+	if len(report.Blocked) == 0 {
+		return mutedStyle.Render("Nothing is blocked")
+	}
+
+	t := newTable(4, "Issue", "Status", "Summary", "Blocked By")
+	for _, blocked := range report.Blocked {
+		t.Row(
+			lipgloss.NewStyle().Foreground(colorBlocked).Render(blocked.Key),
+			blocked.Status,
+			truncate(blocked.Summary, summaryWidth),
+			strings.Join(blocked.Blockers, ", "),
+		)
+	}
+	return t.Render()
+}
+
+func truncate(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	return string(runes[:width-1]) + "…"
+}
@@ -0,0 +1,149 @@
+// Package report summarizes an epic for the terminal: the same stats,
+// breakdowns and blockers the web interface shows.
+package report
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// Report is everything shown about an epic, ready to be rendered or encoded
+type Report struct {
+	Epic         string              `json:"epic"`
+	Summary      string              `json:"summary,omitempty"`
+	Stats        jira.EpicStats      `json:"stats"`
+	StatusCounts []StatusCount       `json:"statusCounts"`
+	TypeCounts   []TypeCount         `json:"typeCounts"`
+	Assignees    []AssigneeLoad      `json:"assignees"`
+	Blocked      []jira.BlockedIssue `json:"blocked"`
+	FetchedAt    time.Time           `json:"fetchedAt"`
+}
+
+type StatusCount struct {
+	Status         string `json:"status"`
+	Classification string `json:"classification"`
+	Count          uint   `json:"count"`
+}
+
+type TypeCount struct {
+	Type  string `json:"type"`
+	Count int    `json:"count"`
+}
+
+// AssigneeLoad is the epic's work assigned to one person. Unassigned issues
+// are grouped under an assignee without an account ID.
+type AssigneeLoad struct {
+	jira.Assignee
+	ToDo        int     `json:"toDo"`
+	InProgress  int     `json:"inProgress"`
+	Done        int     `json:"done"`
+	StoryPoints float64 `json:"storyPoints"`
+}
+
+// Unassigned is the display name of the issues nobody is assigned to
+const Unassigned = "Unassigned"
+
+// classificationOrder sorts statuses the way work flows through them
+var classificationOrder = map[string]int{"new": 0, "indeterminate": 1, "done": 2}
+
+// New builds the report of the epic's issues. The epic itself may be among
+// them, in which case its summary is used as the report's title.
+func New(key string, issues []*model.Ticket, points jira.StoryPointFields, linked []*model.Ticket, fetchedAt time.Time) Report {
+	report := Report{
+		Epic:      key,
+		Stats:     jira.CalculateStats(issues, points),
+		Assignees: assigneeLoads(issues, points),
+		Blocked:   jira.BlockedIssues(jira.BuildGraph(issues, key, points, linked)),
+		FetchedAt: fetchedAt,
+	}
+
+	for _, issue := range issues {
+		if issue.Key == key {
+			report.Summary = issue.Fields.Summary
+			break
+		}
+	}
+
+	for status, count := range jira.CalculateStatusCounts(issues) {
+		report.StatusCounts = append(report.StatusCounts, StatusCount{
+			Status:         status,
+			Classification: count.Classification,
+			Count:          count.Count,
+		})
+	}
+	slices.SortFunc(report.StatusCounts, func(a, b StatusCount) int {
+		return cmp.Or(
+			cmp.Compare(ClassificationRank(a.Classification), ClassificationRank(b.Classification)),
+			cmp.Compare(b.Count, a.Count),
+			strings.Compare(a.Status, b.Status),
+		)
+	})
+
+	for issueType, count := range jira.CalculateTypeCounts(issues) {
+		report.TypeCounts = append(report.TypeCounts, TypeCount{Type: issueType, Count: count})
+	}
+	slices.SortFunc(report.TypeCounts, func(a, b TypeCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), strings.Compare(a.Type, b.Type))
+	})
+
+	return report
+}
+
+// ClassificationRank orders status classifications the way work flows
+// through them, unknown ones last.
+func ClassificationRank(classification string) int {
+	if order, ok := classificationOrder[classification]; ok {
+		return order
+	}
+	return len(classificationOrder)
+}
+
+// assigneeLoads counts each assignee's issues by status category, sorted by
+// name with the unassigned issues last.
+func assigneeLoads(issues []*model.Ticket, points jira.StoryPointFields) []AssigneeLoad {
+	loads := make(map[string]*AssigneeLoad)
+	for _, assignee := range jira.ExtractAssignees(issues) {
+		loads[assignee.AccountID] = &AssigneeLoad{Assignee: assignee}
+	}
+
+	for _, issue := range issues {
+		id := issue.Fields.Assignee.ID()
+		load, ok := loads[id]
+		if !ok {
+			load = &AssigneeLoad{Assignee: jira.Assignee{DisplayName: Unassigned}}
+			loads[id] = load
+		}
+
+		switch issue.Fields.StatusCategory.Key {
+		case "new":
+			load.ToDo++
+		case "indeterminate":
+			load.InProgress++
+		case "done":
+			load.Done++
+		}
+		load.StoryPoints += points.Of(issue)
+	}
+
+	result := make([]AssigneeLoad, 0, len(loads))
+	for _, load := range loads {
+		result = append(result, *load)
+	}
+	slices.SortFunc(result, func(a, b AssigneeLoad) int {
+		aUnassigned, bUnassigned := a.AccountID == "", b.AccountID == ""
+		if aUnassigned != bUnassigned {
+			if aUnassigned {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(strings.ToLower(a.DisplayName), strings.ToLower(b.DisplayName))
+	})
+
+	return result
+}
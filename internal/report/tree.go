@@ -0,0 +1,113 @@
+package report
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/lipgloss/tree"
+)
+
+// DependencyTree draws the blocking links of the graph as a tree under the
+// epic: the issues nothing blocks at the top, each followed by the issues it
+// blocks. An issue with several blockers is drawn in full under the first
+// one and marked as seen under the others, which also keeps cycles finite.
+// Blockers from outside the epic only appear when they block one of its
+// issues.
+func DependencyTree(graph jira.Graph, epic string) string {
+	nodes := graph.NodesByID()
+
+	blocks := make(map[string][]string)
+	blocked := make(map[string]bool)
+	for _, edge := range graph.Edges {
+		if edge.Type != "Blocks" || slices.Contains(blocks[edge.From], edge.To) {
+			continue
+		}
+		blocks[edge.From] = append(blocks[edge.From], edge.To)
+		blocked[edge.To] = true
+
+		// Issues outside the epic are only known through their links
+		if _, ok := nodes[edge.From]; !ok {
+			nodes[edge.From] = jira.GraphNode{ID: edge.From, Status: edge.Status, Classification: edge.Classification, External: true}
+		}
+	}
+	for _, successors := range blocks {
+		slices.SortFunc(successors, jira.CompareKeys)
+	}
+
+	var keys []string
+	for key, node := range nodes {
+		if key == epic || (node.External && len(blocks[key]) == 0) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	slices.SortFunc(keys, jira.CompareKeys)
+
+	// The epic is a bare node unless it was fetched along with its issues
+	rootLabel := titleStyle.Render(epic)
+	if node := nodes[epic]; node.Classification != "epic" && node.Label != "" {
+		rootLabel += " " + lipgloss.NewStyle().Bold(true).Render(truncate(node.Label, 50))
+	}
+
+	root := tree.Root(rootLabel).
+		Enumerator(tree.RoundedEnumerator).
+		EnumeratorStyle(mutedStyle.PaddingRight(1))
+
+	seen := make(map[string]bool)
+	var branch func(key string) any
+	branch = func(key string) any {
+		label := treeLabel(nodes[key])
+		if seen[key] {
+			return label + mutedStyle.Render(" ↑")
+		}
+		seen[key] = true
+
+		if len(blocks[key]) == 0 {
+			return label
+		}
+
+		t := tree.Root(label)
+		for _, successor := range blocks[key] {
+			t.Child(branch(successor))
+		}
+		return t
+	}
+
+	for _, key := range keys {
+		if !blocked[key] {
+			root.Child(branch(key))
+		}
+	}
+
+	// Whatever is left is only blocked from within a cycle
+	for _, key := range keys {
+		if !seen[key] {
+			root.Child(branch(key))
+		}
+	}
+
+	return root.String()
+}
+
+func treeLabel(node jira.GraphNode) string {
+	label := StatusStyle(node.Classification).Bold(true).Render(node.ID)
+	label += " " + StatusStyle(node.Classification).Render("["+node.Status+"]")
+	if node.Label != "" {
+		label += " " + truncate(node.Label, 50)
+	}
+	if node.StoryPoints > 0 {
+		label += mutedStyle.Render(" · " + strconv.FormatFloat(node.StoryPoints, 'f', -1, 64) + " pts")
+	}
+	if node.External {
+		if node.Epic != "" {
+			label += mutedStyle.Render(fmt.Sprintf(" (%s)", node.Epic))
+		} else {
+			label += mutedStyle.Render(" (outside the epic)")
+		}
+	}
+	return label
+}
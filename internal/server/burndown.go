@@ -0,0 +1,68 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/charmbracelet/log"
+)
+
+// maxBurnDays caps the length of burndown series.
+const maxBurnDays = 1000
+
+// handleBurndown serves the daily burndown and burnup series of the epic,
+// rebuilt from issue changelogs. The range defaults to the day the first
+// issue was added until today and can be set with ?from= and ?to=.
+func (s *Server) handleBurndown(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.fetch.timeout)
+	defer cancel()
+
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	to, err := parseTimeParam(r, "to")
+	if err != nil {
+		writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	points := s.points.Fields(ctx)
+	query := s.epicQueryFrom(r, points)
+	query.Expand = []string{jira.ExpandChangelog}
+
+	result, err := s.epics.Epic(ctx, query)
+	if err != nil {
+		log.Error("Error listing epic issues", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	categories, err := s.statusCategories(ctx, result.Issues)
+	if err != nil {
+		log.Error("Error listing statuses", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	if to.IsZero() {
+		to = time.Now()
+	}
+	if from.IsZero() {
+		from = jira.EarliestAdded(result.Issues, query.Key)
+	}
+	if from.IsZero() || from.After(to) {
+		from = to
+	}
+	if limit := to.AddDate(0, 0, -maxBurnDays); from.Before(limit) {
+		from = limit
+	}
+
+	writeCacheHeaders(w, result)
+	writeJSON(w, r, jira.BuildBurnChart(result.Issues, query.Key, categories, points, from, to))
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/epics"
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// epicQueryFrom reads the epic key from the path, extra fields from
+// ?fields=labels,priority and a cache bypass from ?refresh=true.
+func (s *Server) epicQueryFrom(r *http.Request, points jira.StoryPointFields) epics.Query {
+	query := epics.Query{
+		Key:    r.PathValue("ticket"),
+		Fields: jira.DefaultFields(points),
+	}
+
+	if extra := r.URL.Query().Get("fields"); extra != "" {
+		query.Fields = jira.MergeFields(query.Fields, strings.Split(extra, ","))
+	}
+
+	query.Refresh, _ = strconv.ParseBool(r.URL.Query().Get("refresh"))
+
+	return query
+}
+
+// withEpicKey rejects requests whose {ticket} isn't an issue key before
+// they reach the handler, since it ends up in JQL.
+func withEpicKey(handler http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if ticket := r.PathValue("ticket"); !jira.ValidIssueKey(ticket) {
+			writeStatusProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid epic key %q, expected one like ABC-123", ticket))
+			return
+		}
+		handler(w, r)
+	}
+}
+
+// graphHops reads ?hops=, how many links away from the epic issues outside
+// of it are fetched into the graph.
+func graphHops(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("hops")
+	if value == "" {
+		return 0, nil
+	}
+
+	hops, err := strconv.Atoi(value)
+	if err != nil || hops < 0 || hops > epics.MaxHops {
+		return 0, fmt.Errorf("invalid hops %q, expected 0 to %d", value, epics.MaxHops)
+	}
+
+	return hops, nil
+}
+
+// writeCacheHeaders tells the client how old the data is and whether it
+// came from the cache.
+func writeCacheHeaders(w http.ResponseWriter, result epics.Result) {
+	age := max(int(time.Now().Sub(result.FetchedAt).Seconds()), 0)
+
+	w.Header().Set("Age", strconv.Itoa(age))
+	w.Header().Set("Last-Modified", result.FetchedAt.UTC().Format(http.TimeFormat))
+	w.Header().Set("X-Cache", string(result.Status))
+}
+
+// statusCategories returns the category of every status, fetching the list
+// from Jira the first time it's needed.
+func (s *Server) statusCategories(ctx context.Context, issues []*model.Ticket) (jira.StatusCategories, error) {
+	s.statusesMu.Lock()
+	defer s.statusesMu.Unlock()
+
+	if s.statuses == nil {
+		statuses, err := s.source.ListStatuses(ctx)
+		if err != nil {
+			return nil, err
+		}
+		s.statuses = statuses
+	}
+
+	return jira.NewStatusCategories(s.statuses).Learn(issues), nil
+}
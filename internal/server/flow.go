@@ -0,0 +1,37 @@
+package server
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/charmbracelet/log"
+)
+
+// handleFlow serves the lead and cycle time analytics of the epic, computed
+// from issue changelogs.
+func (s *Server) handleFlow(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.fetch.timeout)
+	defer cancel()
+
+	query := s.epicQueryFrom(r, s.points.Fields(ctx))
+	query.Expand = []string{jira.ExpandChangelog}
+
+	result, err := s.epics.Epic(ctx, query)
+	if err != nil {
+		log.Error("Error listing epic issues", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	categories, err := s.statusCategories(ctx, result.Issues)
+	if err != nil {
+		log.Error("Error listing statuses", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeCacheHeaders(w, result)
+	writeJSON(w, r, jira.CalculateFlowStats(result.Issues, categories))
+}
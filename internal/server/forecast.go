@@ -0,0 +1,139 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/epics"
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/charmbracelet/log"
+)
+
+const (
+	// maxForecastTrials caps ?trials= so a single request can't hog the CPU.
+	maxForecastTrials = 100000
+	// maxForecastWeeks caps how far back ?weeks= samples throughput.
+	maxForecastWeeks = 104
+)
+
+// handleForecast serves a Monte Carlo forecast of when the epic's remaining
+// work gets done, from the weekly throughput of the epic or of the
+// configured reference JQL. It's measured in ?unit=issues or points, and
+// ?seed= and ?start= make it reproducible.
+func (s *Server) handleForecast(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.fetch.timeout)
+	defer cancel()
+
+	unit := jira.ForecastUnit(r.URL.Query().Get("unit"))
+	switch unit {
+	case "":
+		unit = jira.ForecastIssues
+	case jira.ForecastIssues, jira.ForecastPoints:
+	default:
+		writeStatusProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid unit %q, expected issues or points", unit))
+		return
+	}
+
+	opts := jira.ForecastOptions{
+		Trials: s.config.forecast.trials,
+		Seed:   rand.Uint64(),
+	}
+	weeks := s.config.forecast.weeks
+
+	var err error
+	if opts.Start, err = parseTimeParam(r, "start"); err != nil {
+		writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+	if opts.Start.IsZero() {
+		opts.Start = jira.StartOfDay(time.Now())
+	}
+
+	if value := r.URL.Query().Get("seed"); value != "" {
+		if opts.Seed, err = strconv.ParseUint(value, 10, 64); err != nil {
+			writeStatusProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid seed %q", value))
+			return
+		}
+	}
+
+	if value := r.URL.Query().Get("trials"); value != "" {
+		if opts.Trials, err = strconv.Atoi(value); err != nil || opts.Trials < 1 || opts.Trials > maxForecastTrials {
+			writeStatusProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid trials %q, expected 1 to %d", value, maxForecastTrials))
+			return
+		}
+	}
+
+	if value := r.URL.Query().Get("weeks"); value != "" {
+		if weeks, err = strconv.Atoi(value); err != nil || weeks < 1 || weeks > maxForecastWeeks {
+			writeStatusProblem(w, r, http.StatusBadRequest, fmt.Sprintf("invalid weeks %q, expected 1 to %d", value, maxForecastWeeks))
+			return
+		}
+	}
+
+	points := s.points.Fields(ctx)
+	query := s.epicQueryFrom(r, points)
+	query.Expand = []string{jira.ExpandChangelog}
+
+	result, err := s.epics.Epic(ctx, query)
+	if err != nil {
+		log.Error("Error listing epic issues", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	reference := result.Issues
+	if s.config.forecast.jql != "" {
+		// Reference issues aren't expanded, they're dated by resolution.
+		referenceResult, err := s.epics.Epic(ctx, epics.Query{
+			JQL:     s.config.forecast.jql,
+			Fields:  query.Fields,
+			Refresh: query.Refresh,
+		})
+		if err != nil {
+			log.Error("Error listing forecast reference issues", "err", err)
+			writeProblem(w, r, err)
+			return
+		}
+		reference = referenceResult.Issues
+	}
+
+	categories, err := s.statusCategories(ctx, result.Issues)
+	if err != nil {
+		log.Error("Error listing statuses", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	throughput := jira.WeeklyThroughput(jira.Completions(reference, categories, points), unit, opts.Start, weeks)
+	remaining, unestimated := jira.Remaining(result.Issues, unit, points)
+
+	forecast, err := jira.RunForecast(throughput, remaining, unit, opts)
+	if errors.Is(err, jira.ErrNoThroughput) {
+		writeStatusProblem(w, r, http.StatusUnprocessableEntity,
+			fmt.Sprintf("nothing was done in the last %d weeks, so there is no throughput to forecast from", weeks))
+		return
+	}
+	if err != nil {
+		log.Error("Error forecasting epic", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	forecast.Unestimated = unestimated
+	if forecast.Capped > 0 {
+		log.Warn("Forecast trials never finished",
+			"epic", query.Key,
+			"capped", forecast.Capped,
+			"trials", forecast.Trials,
+		)
+	}
+
+	writeCacheHeaders(w, result)
+	writeJSON(w, r, forecast)
+}
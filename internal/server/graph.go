@@ -0,0 +1,60 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/charmbracelet/log"
+)
+
+// handleGraph serves the dependency graph of the epic on its own, as JSON or
+// exported with ?format=dot|mermaid|graphml. ?hops= expands it like the epic
+// endpoint does.
+func (s *Server) handleGraph(w http.ResponseWriter, r *http.Request) {
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.fetch.timeout)
+	defer cancel()
+
+	format := jira.GraphJSON
+	if value := r.URL.Query().Get("format"); value != "" {
+		var err error
+		if format, err = jira.ParseGraphFormat(value); err != nil {
+			writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
+	}
+
+	hops, err := graphHops(r)
+	if err != nil {
+		writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	points := s.points.Fields(ctx)
+	query := s.epicQueryFrom(r, points)
+
+	result, linked, err := s.epics.EpicWithLinks(ctx, query, hops)
+	if err != nil {
+		log.Error("Error listing epic issues", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	graph := jira.BuildGraph(result.Issues, query.Key, points, linked)
+
+	var body bytes.Buffer
+	if err := jira.WriteGraph(&body, graph, format); err != nil {
+		log.Error("Error exporting graph", "format", format, "err", err)
+		writeStatusProblem(w, r, http.StatusInternalServerError, "The graph could not be exported")
+		return
+	}
+
+	writeCacheHeaders(w, result)
+	w.Header().Set("Content-Type", format.ContentType())
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(body.Bytes()); err != nil {
+		log.Error("Error writing response", "err", err)
+	}
+}
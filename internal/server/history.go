@@ -0,0 +1,120 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/epics"
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+	"github.com/Fuabioo/altalune/internal/snapshot"
+
+	"github.com/charmbracelet/log"
+)
+
+// recordSnapshot stores the state of a freshly fetched epic, if snapshots
+// are enabled.
+func (s *Server) recordSnapshot(epic string, issues []*model.Ticket, points jira.StoryPointFields) {
+	if s.store == nil {
+		return
+	}
+
+	if err := s.store.Save(snapshot.New(epic, issues, points, time.Now())); err != nil {
+		log.Error("Error saving snapshot", "epic", epic, "err", err)
+	}
+}
+
+// snapshotLoop refetches the configured epics every interval, which records
+// a snapshot of each, until ctx is done.
+func (s *Server) snapshotLoop(ctx context.Context) {
+	ticker := time.NewTicker(s.config.snapshot.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		points := s.points.Fields(ctx)
+		for _, epic := range s.config.snapshot.epics {
+			_, err := s.epics.Epic(ctx, epics.Query{
+				Key:     epic,
+				Fields:  jira.DefaultFields(points),
+				Refresh: true,
+			})
+			if err != nil {
+				log.Error("Error taking scheduled snapshot", "epic", epic, "err", err)
+			}
+		}
+	}
+}
+
+// handleHistory serves the epic's snapshots, optionally limited with
+// ?from= and ?to= (RFC 3339 or YYYY-MM-DD). ?to= is exclusive, except that a
+// date on its own includes the whole day. Per-issue states are included
+// with ?issues=true.
+func (s *Server) handleHistory(w http.ResponseWriter, r *http.Request) {
+	if s.store == nil {
+		writeStatusProblem(w, r, http.StatusNotFound, "Snapshot history is disabled, start the server with --snapshot-db")
+		return
+	}
+
+	from, err := parseTimeParam(r, "from")
+	if err != nil {
+		writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	to, err := parseEndParam(r, "to")
+	if err != nil {
+		writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	withIssues, _ := strconv.ParseBool(r.URL.Query().Get("issues"))
+
+	snapshots, err := s.store.History(r.PathValue("ticket"), from, to, withIssues)
+	if err != nil {
+		log.Error("Error reading snapshot history", "err", err)
+		writeProblem(w, r, err)
+		return
+	}
+
+	writeJSON(w, r, snapshots)
+}
+
+func parseTimeParam(r *http.Request, name string) (time.Time, error) {
+	value := r.URL.Query().Get(name)
+	if value == "" {
+		return time.Time{}, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("invalid %s %q, expected RFC 3339 or YYYY-MM-DD", name, value)
+}
+
+// parseEndParam reads the exclusive end of a range like parseTimeParam. A
+// date on its own ends at the start of the next day, so that the day named
+// is included.
+func parseEndParam(r *http.Request, name string) (time.Time, error) {
+	t, err := parseTimeParam(r, name)
+	if err != nil || t.IsZero() {
+		return t, err
+	}
+
+	if _, err := time.Parse(time.DateOnly, r.URL.Query().Get(name)); err == nil {
+		return t.AddDate(0, 0, 1), nil
+	}
+
+	return t, nil
+}
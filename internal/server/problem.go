@@ -0,0 +1,119 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+
+	"github.com/charmbracelet/log"
+)
+
+// Problem is an RFC 7807 problem details body.
+type Problem struct {
+	Type     string         `json:"type"`
+	Title    string         `json:"title"`
+	Status   int            `json:"status"`
+	Detail   string         `json:"detail,omitempty"`
+	Instance string         `json:"instance,omitempty"`
+	Kind     jira.ErrorKind `json:"kind,omitempty"`
+	Messages []string       `json:"messages,omitempty"`
+}
+
+var problemTitles = map[jira.ErrorKind]string{
+	jira.ErrorKindAuth:         "Jira rejected the configured credentials",
+	jira.ErrorKindPermission:   "Jira denied access to the requested resource",
+	jira.ErrorKindNotFound:     "Not found in Jira",
+	jira.ErrorKindRateLimited:  "Jira rate limit exceeded",
+	jira.ErrorKindUpstreamDown: "Jira is unavailable",
+	jira.ErrorKindBadRequest:   "Jira rejected the request",
+}
+
+var problemStatuses = map[jira.ErrorKind]int{
+	jira.ErrorKindAuth:         http.StatusUnauthorized,
+	jira.ErrorKindPermission:   http.StatusForbidden,
+	jira.ErrorKindNotFound:     http.StatusNotFound,
+	jira.ErrorKindRateLimited:  http.StatusTooManyRequests,
+	jira.ErrorKindUpstreamDown: http.StatusBadGateway,
+	jira.ErrorKindBadRequest:   http.StatusBadRequest,
+}
+
+// problemFromError maps err to the problem the client should see. Jira
+// errors keep their classification, invalid issue keys become 400, timeouts
+// 504 and anything else is an opaque 500.
+func problemFromError(err error) Problem {
+	var apiErr *jira.APIError
+	switch {
+	case errors.As(err, &apiErr):
+		kind := apiErr.Kind()
+		status, ok := problemStatuses[kind]
+		if !ok {
+			status = http.StatusBadGateway
+		}
+		detail := apiErr.Error()
+		if messages := apiErr.Messages(); len(messages) > 0 {
+			detail = strings.Join(messages, "; ")
+		}
+		return Problem{
+			Type:     "/problems/" + string(kind),
+			Title:    problemTitles[kind],
+			Status:   status,
+			Detail:   detail,
+			Kind:     kind,
+			Messages: apiErr.Messages(),
+		}
+	case errors.Is(err, jira.ErrInvalidKey):
+		return Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusBadRequest),
+			Status: http.StatusBadRequest,
+			Detail: err.Error(),
+		}
+	case errors.Is(err, context.DeadlineExceeded):
+		return Problem{
+			Type:   "/problems/timeout",
+			Title:  "Timed out waiting for Jira",
+			Status: http.StatusGatewayTimeout,
+			Detail: err.Error(),
+		}
+	default:
+		return Problem{
+			Type:   "about:blank",
+			Title:  http.StatusText(http.StatusInternalServerError),
+			Status: http.StatusInternalServerError,
+		}
+	}
+}
+
+func writeProblem(w http.ResponseWriter, r *http.Request, err error) {
+	var apiErr *jira.APIError
+	if errors.As(err, &apiErr) && apiErr.RetryAfter != "" {
+		w.Header().Set("Retry-After", apiErr.RetryAfter)
+	}
+
+	writeProblemBody(w, r, problemFromError(err))
+}
+
+// writeStatusProblem reports a problem raised by the server itself rather
+// than by Jira, such as an invalid query parameter.
+func writeStatusProblem(w http.ResponseWriter, r *http.Request, status int, detail string) {
+	writeProblemBody(w, r, Problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+}
+
+func writeProblemBody(w http.ResponseWriter, r *http.Request, problem Problem) {
+	problem.Instance = r.URL.Path
+
+	w.Header().Set("Content-Type", "application/problem+json")
+	w.WriteHeader(problem.Status)
+	if err := json.NewEncoder(w).Encode(problem); err != nil {
+		log.Error("Error writing problem response", "err", err)
+	}
+}
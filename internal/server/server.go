@@ -7,10 +7,13 @@ import (
 	"fmt"
 	"io/fs"
 	"net/http"
+	"sync"
 	"time"
 
+	"github.com/Fuabioo/altalune/internal/epics"
 	"github.com/Fuabioo/altalune/internal/jira"
 	"github.com/Fuabioo/altalune/internal/model"
+	"github.com/Fuabioo/altalune/internal/snapshot"
 
 	"github.com/charmbracelet/log"
 )
@@ -20,6 +23,30 @@ type (
 		superDebug bool
 		server     serverConfig
 		jira       jira.Config
+		source     jira.IssueSource
+		fetch      fetchConfig
+		cache      cacheConfig
+		snapshot   snapshotConfig
+		forecast   forecastConfig
+	}
+	forecastConfig struct {
+		jql    string
+		weeks  int
+		trials int
+	}
+	snapshotConfig struct {
+		path     string
+		interval time.Duration
+		epics    []string
+	}
+	cacheConfig struct {
+		ttl      time.Duration
+		staleFor time.Duration
+	}
+	fetchConfig struct {
+		pageSize    uint
+		concurrency int
+		timeout     time.Duration
 	}
 	serverConfig struct {
 		host   string
@@ -30,7 +57,14 @@ type (
 	Server struct {
 		config config
 		server *http.Server
-		client *jira.Client
+		source jira.IssueSource
+		points *jira.StoryPointResolver
+		epics  *epics.Loader
+		store  *snapshot.Store
+		stop   context.CancelFunc
+
+		statusesMu sync.Mutex
+		statuses   []model.Status
 	}
 	Option func(*config)
 )
@@ -48,14 +82,98 @@ func ServerAssets(assets fs.FS) Option {
 	}
 }
 
-func ServerJira(workspace string, email string, token string) Option {
+func ServerJira(host string, email string, token string) Option {
 	return func(c *config) {
-		c.jira.Workspace = workspace
+		c.jira.Host = host
 		c.jira.Email = email
 		c.jira.Token = token
 	}
 }
 
+// ServerIssueSource replaces the Jira client built from ServerJira, e.g.
+// with a MemorySource loaded from fixtures.
+func ServerIssueSource(source jira.IssueSource) Option {
+	return func(c *config) {
+		c.source = source
+	}
+}
+
+func ServerJiraAuth(auth string) Option {
+	return func(c *config) {
+		c.jira.Auth = jira.AuthMode(auth)
+	}
+}
+
+// ServerJiraAPI selects the REST API version and, optionally, a custom base
+// path for Jira instances served under a context path.
+func ServerJiraAPI(version string, basePath string) Option {
+	return func(c *config) {
+		c.jira.APIVersion = version
+		c.jira.BasePath = basePath
+	}
+}
+
+// ServerFetch controls how epics are paged through: the page size, how many
+// pages are fetched at once and the overall time allowed.
+func ServerFetch(pageSize uint, concurrency int, timeout time.Duration) Option {
+	return func(c *config) {
+		c.fetch.pageSize = pageSize
+		c.fetch.concurrency = concurrency
+		c.fetch.timeout = timeout
+	}
+}
+
+// ServerCache keeps fetched epics fresh for ttl, then serves them stale for
+// up to staleFor while they are refetched in the background.
+func ServerCache(ttl time.Duration, staleFor time.Duration) Option {
+	return func(c *config) {
+		c.cache.ttl = ttl
+		c.cache.staleFor = staleFor
+	}
+}
+
+// ServerSnapshots records a snapshot of every epic fetched from Jira in the
+// database at path. With a positive interval the given epics are also
+// refetched, and so snapshotted, on that schedule.
+func ServerSnapshots(path string, interval time.Duration, epics []string) Option {
+	return func(c *config) {
+		c.snapshot.path = path
+		c.snapshot.interval = interval
+		c.snapshot.epics = epics
+	}
+}
+
+// ServerForecast sets where forecasts take their throughput from: the last
+// weeks of the epic itself, or of the issues matching jql when given, and
+// how many trials are simulated by default.
+func ServerForecast(jql string, weeks int, trials int) Option {
+	return func(c *config) {
+		c.forecast.jql = jql
+		c.forecast.weeks = weeks
+		c.forecast.trials = trials
+	}
+}
+
+// ServerStoryPointField pins the story point field, by ID or name, instead
+// of discovering it.
+func ServerStoryPointField(field string) Option {
+	return func(c *config) {
+		c.jira.StoryPointField = field
+	}
+}
+
+func ServerJiraRetry(retry jira.RetryPolicy) Option {
+	return func(c *config) {
+		c.jira.Retry = retry
+	}
+}
+
+func ServerSearchAPI(api string) Option {
+	return func(c *config) {
+		c.jira.SearchAPI = jira.SearchAPI(api)
+	}
+}
+
 func ServerSuperDebug(superDebug bool) Option {
 	return func(c *config) {
 		c.superDebug = superDebug
@@ -66,6 +184,19 @@ func NewServer(options ...Option) (*Server, error) {
 	cfg := &config{
 		server: serverConfig{},
 		jira:   jira.Config{},
+		fetch: fetchConfig{
+			pageSize:    50,
+			concurrency: 4,
+			timeout:     time.Second * 30,
+		},
+		cache: cacheConfig{
+			ttl:      time.Minute * 2,
+			staleFor: time.Minute * 10,
+		},
+		forecast: forecastConfig{
+			weeks:  12,
+			trials: 10000,
+		},
 	}
 
 	for _, option := range options {
@@ -78,12 +209,41 @@ func NewServer(options ...Option) (*Server, error) {
 	}
 
 	cfg.jira.SuperDebug = cfg.superDebug
+	cfg.jira = cfg.jira.WithDefaults()
 
-	return &Server{
+	source := cfg.source
+	if source == nil {
+		source = jira.NewClient(cfg.jira)
+	}
+
+	var store *snapshot.Store
+	if cfg.snapshot.path != "" {
+		var err error
+		if store, err = snapshot.Open(cfg.snapshot.path); err != nil {
+			return nil, err
+		}
+	}
+
+	s := &Server{
 		config: *cfg,
 		server: server,
-		client: jira.NewClient(cfg.jira),
-	}, nil
+		source: source,
+		points: jira.NewStoryPointResolver(source, cfg.jira),
+		store:  store,
+	}
+
+	s.epics = epics.NewLoader(source, s.points, epics.Config{
+		PageSize:    cfg.fetch.pageSize,
+		Concurrency: cfg.fetch.concurrency,
+		Timeout:     cfg.fetch.timeout,
+		CacheTTL:    cfg.cache.ttl,
+		CacheStale:  cfg.cache.staleFor,
+		OnFetch: func(ctx context.Context, epic string, issues []*model.Ticket) {
+			s.recordSnapshot(epic, issues, s.points.Fields(ctx))
+		},
+	})
+
+	return s, nil
 }
 
 func (s *Server) Start() error {
@@ -93,75 +253,104 @@ func (s *Server) Start() error {
 		"port", s.config.server.port,
 	)
 
+	// Resolve the story point field up front so the first epic doesn't pay
+	// for it. Failures are retried on demand.
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*10)
+	s.points.Fields(ctx)
+	cancel()
+
+	ctx, s.stop = context.WithCancel(context.Background())
+	if s.store != nil && s.config.snapshot.interval > 0 {
+		go s.snapshotLoop(ctx)
+	}
+
+	s.server.Handler = s.routes()
+
+	err := s.server.ListenAndServe()
+	if err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+
+	return nil
+}
+
+// routes registers the API and the frontend
+func (s *Server) routes() http.Handler {
 	router := http.NewServeMux()
 
 	router.HandleFunc("/api/ping", func(w http.ResponseWriter, r *http.Request) {
-		err := s.client.Ping(r.Context())
+		err := s.source.Ping(r.Context())
 		if err != nil {
 			log.Error("Error pinging Jira", "err", err)
-			w.WriteHeader(http.StatusInternalServerError)
+			writeProblem(w, r, err)
 			return
 		}
 
-		w.Write([]byte(`{}`))
+		var response struct {
+			Retries *jira.RetryStats `json:"retries,omitempty"`
+		}
+
+		if counter, ok := s.source.(interface{ RetryStats() jira.RetryStats }); ok {
+			retries := counter.RetryStats()
+			response.Retries = &retries
+		}
+
+		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(response)
 	})
 
-	router.HandleFunc("/api/epic/{ticket}", func(w http.ResponseWriter, r *http.Request) {
-		ctx, cancel := context.WithTimeout(r.Context(), time.Second*10)
+	router.HandleFunc("/api/epic/{ticket}", withEpicKey(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), s.config.fetch.timeout)
 		defer cancel()
 
 		ticket := r.PathValue("ticket")
 
 		var response struct {
-			Stats        jira.EpicStats    `json:"stats"`
-			Graph        jira.Graph        `json:"graph"`
-			StatusCounts jira.StatusCounts `json:"statusCounts"`
-			TypeCounts   jira.TypeCounts   `json:"typeCounts"`
-			Issues       []*model.Ticket   `json:"issues"`
-			Epic         *model.Ticket     `json:"epic"`
-			All          []*model.Ticket   `json:"tickets"`
-			Total        int               `json:"total"`
-			JiraBaseURL  string            `json:"jiraBaseUrl"`
-			Assignees    []jira.Assignee   `json:"assignees"`
+			Stats        jira.EpicStats        `json:"stats"`
+			Graph        jira.Graph            `json:"graph"`
+			CriticalPath jira.CriticalPath     `json:"criticalPath"`
+			Warnings     []jira.Warning        `json:"warnings"`
+			Dependencies jira.EpicDependencies `json:"epicDependencies"`
+			StatusCounts jira.StatusCounts     `json:"statusCounts"`
+			TypeCounts   jira.TypeCounts       `json:"typeCounts"`
+			Issues       []*model.Ticket       `json:"issues"`
+			Epic         *model.Ticket         `json:"epic"`
+			All          []*model.Ticket       `json:"tickets"`
+			Total        int                   `json:"total"`
+			JiraBaseURL  string                `json:"jiraBaseUrl"`
+			Assignees    []jira.Assignee       `json:"assignees"`
+			FetchedAt    time.Time             `json:"fetchedAt"`
 		}
 
-		startAt := uint(0)
-		pageSize := uint(50)
-
-		for {
-			result, err := s.client.ListEpicIssues(ctx, jira.ListEpicRequest{
-				EpicID:   ticket,
-				StartAt:  startAt,
-				PageSize: pageSize,
-			})
-			if err != nil {
-				log.Error("Error listing epic issues", "err", err)
-				w.WriteHeader(http.StatusInternalServerError)
-				return
-			}
-
-			log.Info("Epic issues listed",
-				"startedAt", result.StartAt,
-				"total", result.Total,
-				"max", result.MaxResults,
-			)
-			response.All = append(response.All, result.Issues...)
+		hops, err := graphHops(r)
+		if err != nil {
+			writeStatusProblem(w, r, http.StatusBadRequest, err.Error())
+			return
+		}
 
-			if result.Total <= startAt+pageSize {
-				break
-			}
+		points := s.points.Fields(ctx)
+		query := s.epicQueryFrom(r, points)
 
-			startAt += pageSize
+		result, linked, err := s.epics.EpicWithLinks(ctx, query, hops)
+		if err != nil {
+			log.Error("Error listing epic issues", "err", err)
+			writeProblem(w, r, err)
+			return
 		}
 
+		response.All = result.Issues
+		response.FetchedAt = result.FetchedAt
 		response.Total = len(response.All)
-		response.Stats = jira.CalculateStats(response.All)
+		response.Stats = jira.CalculateStats(response.All, points)
 		response.StatusCounts = jira.CalculateStatusCounts(response.All)
 		response.TypeCounts = jira.CalculateTypeCounts(response.All)
-		response.Graph = jira.BuildGraph(response.All, ticket)
+		response.Graph = jira.BuildGraph(response.All, ticket, points, linked)
+		response.CriticalPath = jira.FindCriticalPath(response.Graph, criticalPathWeight(r, response.Stats))
+		response.Warnings = jira.Diagnose(response.Graph)
+		response.Dependencies = jira.SummarizeEpicDependencies(response.Graph)
 		response.Issues = response.All
-		response.JiraBaseURL = s.config.jira.Workspace
+		response.JiraBaseURL = s.config.jira.Host
 		response.Assignees = jira.ExtractAssignees(response.All)
 
 		// Find epic in the issues (if it exists)
@@ -172,27 +361,66 @@ func (s *Server) Start() error {
 			}
 		}
 
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		json.NewEncoder(w).Encode(response)
-	})
+		writeCacheHeaders(w, result)
+		writeJSON(w, r, response)
+	}))
+
+	router.HandleFunc("/api/epic/{ticket}/history", withEpicKey(s.handleHistory))
+	router.HandleFunc("/api/epic/{ticket}/burndown", withEpicKey(s.handleBurndown))
+	router.HandleFunc("/api/epic/{ticket}/flow", withEpicKey(s.handleFlow))
+	router.HandleFunc("/api/epic/{ticket}/forecast", withEpicKey(s.handleForecast))
+	router.HandleFunc("/api/epic/{ticket}/graph", withEpicKey(s.handleGraph))
 
 	frontendFS := http.FileServer(http.FS(s.config.server.assets))
 
 	router.Handle("/", frontendFS)
 
-	s.server.Handler = router
-
-	err := s.server.ListenAndServe()
-	if err != nil && !errors.Is(err, http.ErrServerClosed) {
-		return err
-	}
-
-	return nil
+	return router
 }
 
 func (s *Server) Close() {
+	if s.stop != nil {
+		s.stop()
+	}
+
 	if err := s.server.Close(); err != nil {
 		log.Error("Error closing server", "err", err)
 	}
+
+	if s.store != nil {
+		if err := s.store.Close(); err != nil {
+			log.Error("Error closing snapshot store", "err", err)
+		}
+	}
+}
+
+// criticalPathWeight reads ?weight=points|count, defaulting to points unless
+// nothing in the epic is estimated.
+func criticalPathWeight(r *http.Request, stats jira.EpicStats) jira.PathWeight {
+	switch weight := jira.PathWeight(r.URL.Query().Get("weight")); weight {
+	case jira.WeightPoints, jira.WeightCount:
+		return weight
+	}
+
+	if stats.TotalPoints > 0 {
+		return jira.WeightPoints
+	}
+	return jira.WeightCount
+}
+
+// writeJSON encodes v before writing anything, so a value that can't be
+// encoded is reported as a problem instead of a truncated 200.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		log.Error("Error encoding response", "err", err)
+		writeStatusProblem(w, r, http.StatusInternalServerError, "The response could not be encoded")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if _, err := w.Write(append(body, '\n')); err != nil {
+		log.Error("Error writing response", "err", err)
+	}
 }
@@ -0,0 +1,163 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+)
+
+// newTestServer serves the ABC-1 fixture epic
+func newTestServer(t *testing.T, options ...Option) *httptest.Server {
+	t.Helper()
+
+	source, err := jira.LoadMemorySource(os.DirFS("../jira/testdata/ABC-1"))
+	if err != nil {
+		t.Fatalf("LoadMemorySource() error = %v", err)
+	}
+
+	s, err := NewServer(append([]Option{
+		ServerIssueSource(source),
+		ServerAssets(fstest.MapFS{}),
+	}, options...)...)
+	if err != nil {
+		t.Fatalf("NewServer() error = %v", err)
+	}
+
+	t.Cleanup(s.Close)
+
+	server := httptest.NewServer(s.routes())
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestEpic(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/api/epic/ABC-1")
+	if err != nil {
+		t.Fatalf("GET /api/epic/ABC-1 error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if got := resp.Header.Get("X-Cache"); got != "MISS" {
+		t.Errorf("X-Cache = %q, want MISS", got)
+	}
+
+	var body struct {
+		Total int            `json:"total"`
+		Stats jira.EpicStats `json:"stats"`
+		Graph jira.Graph     `json:"graph"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decoding the response: %v", err)
+	}
+
+	if body.Total != 4 {
+		t.Errorf("total = %d, want 4", body.Total)
+	}
+	if body.Stats.Done != 1 || body.Stats.InProgress != 1 || body.Stats.ToDo != 2 {
+		t.Errorf("stats = %+v, want 1 done, 1 in progress and 2 to do", body.Stats)
+	}
+	if body.Stats.TotalPoints != 10 || body.Stats.DonePoints != 3 || body.Stats.Unestimated != 1 {
+		t.Errorf("points = %+v, want 3 of 10 done and 1 unestimated", body.Stats)
+	}
+
+	nodes := make(map[string]jira.GraphNode)
+	for _, node := range body.Graph.Nodes {
+		nodes[node.ID] = node
+	}
+	for _, key := range []string{"ABC-1", "ABC-2", "ABC-3", "ABC-4", "ABC-5"} {
+		if _, ok := nodes[key]; !ok {
+			t.Errorf("graph has no %s node", key)
+		}
+	}
+	if nodes["ABC-3"].StoryPoints != 5 {
+		t.Errorf("ABC-3 has %v story points, want 5", nodes["ABC-3"].StoryPoints)
+	}
+}
+
+func TestEpicNotFound(t *testing.T) {
+	server := newTestServer(t)
+
+	resp, err := http.Get(server.URL + "/api/epic/NOPE-1")
+	if err != nil {
+		t.Fatalf("GET /api/epic/NOPE-1 error = %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("status = %d, want 404", resp.StatusCode)
+	}
+	if got := resp.Header.Get("Content-Type"); got != "application/problem+json" {
+		t.Errorf("Content-Type = %q, want application/problem+json", got)
+	}
+}
+
+func TestEpicInvalidKey(t *testing.T) {
+	server := newTestServer(t)
+
+	for _, path := range []string{
+		"/api/epic/ABC-1%20OR%20project%20=%20X",
+		"/api/epic/ABC/graph",
+		"/api/epic/ABC-1)/history",
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		resp.Body.Close()
+
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("GET %s status = %d, want 400", path, resp.StatusCode)
+		}
+		if got := resp.Header.Get("Content-Type"); got != "application/problem+json" {
+			t.Errorf("GET %s Content-Type = %q, want application/problem+json", path, got)
+		}
+	}
+}
+
+func TestHistoryDateOnlyTo(t *testing.T) {
+	server := newTestServer(t, ServerSnapshots(filepath.Join(t.TempDir(), "snapshots.db"), 0, nil))
+
+	// Fetching the epic records a snapshot of it
+	resp, err := http.Get(server.URL + "/api/epic/ABC-1")
+	if err != nil {
+		t.Fatalf("GET /api/epic/ABC-1 error = %v", err)
+	}
+	resp.Body.Close()
+
+	today := time.Now().UTC()
+	for _, tt := range []struct {
+		to   string
+		want int
+	}{
+		{today.Format(time.DateOnly), 1},
+		{today.AddDate(0, 0, -1).Format(time.DateOnly), 0},
+		{today.Truncate(24 * time.Hour).Format(time.RFC3339), 0},
+	} {
+		resp, err := http.Get(server.URL + "/api/epic/ABC-1/history?to=" + tt.to)
+		if err != nil {
+			t.Fatalf("GET history?to=%s error = %v", tt.to, err)
+		}
+
+		var snapshots []json.RawMessage
+		err = json.NewDecoder(resp.Body).Decode(&snapshots)
+		resp.Body.Close()
+		if err != nil {
+			t.Fatalf("decoding the history: %v", err)
+		}
+		if len(snapshots) != tt.want {
+			t.Errorf("history?to=%s has %d snapshots, want %d", tt.to, len(snapshots), tt.want)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package snapshot
+
+import (
+	"time"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+)
+
+// Snapshot is the state of an epic at a point in time
+type Snapshot struct {
+	Epic         string            `json:"epic"`
+	TakenAt      time.Time         `json:"takenAt"`
+	Stats        jira.EpicStats    `json:"stats"`
+	StatusCounts jira.StatusCounts `json:"statusCounts"`
+	Issues       []IssueState      `json:"issues,omitempty"`
+}
+
+// IssueState is the state of a single issue when the snapshot was taken
+type IssueState struct {
+	Key            string  `json:"key"`
+	Status         string  `json:"status"`
+	Classification string  `json:"classification"`
+	StoryPoints    float64 `json:"storyPoints"`
+}
+
+// New captures the current state of the epic's issues
+func New(epic string, issues []*model.Ticket, points jira.StoryPointFields, takenAt time.Time) Snapshot {
+	snapshot := Snapshot{
+		Epic:         epic,
+		TakenAt:      takenAt,
+		Stats:        jira.CalculateStats(issues, points),
+		StatusCounts: jira.CalculateStatusCounts(issues),
+		Issues:       make([]IssueState, 0, len(issues)),
+	}
+
+	for _, issue := range issues {
+		snapshot.Issues = append(snapshot.Issues, IssueState{
+			Key:            issue.Key,
+			Status:         issue.Fields.Status.Name,
+			Classification: issue.Fields.StatusCategory.Key,
+			StoryPoints:    points.Of(issue),
+		})
+	}
+
+	return snapshot
+}
@@ -0,0 +1,143 @@
+package snapshot
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// snapshotsBucket holds one nested bucket per epic, whose keys are the
+// big-endian UnixNano of each snapshot so they iterate in time order.
+var snapshotsBucket = []byte("snapshots")
+
+// minTime and maxTime bound the times a UnixNano key can hold.
+var (
+	minTime = time.Unix(0, 0)
+	maxTime = time.Unix(0, math.MaxInt64)
+)
+
+// Store persists snapshots in an embedded bbolt database, which is pure Go
+// and keeps the static CGO_ENABLED=0 build working.
+type Store struct {
+	db *bolt.DB
+}
+
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("error opening snapshot database: %w", err)
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(snapshotsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("error initializing snapshot database: %w", err)
+	}
+
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Save records the snapshot, replacing any taken at the exact same time.
+// Snapshots can only be taken between 1970 and 2262.
+func (s *Store) Save(snapshot Snapshot) error {
+	if snapshot.TakenAt.Before(minTime) || snapshot.TakenAt.After(maxTime) {
+		return fmt.Errorf("snapshot taken at %s is out of range", snapshot.TakenAt.Format(time.RFC3339))
+	}
+
+	value, err := json.Marshal(snapshot)
+	if err != nil {
+		return fmt.Errorf("error marshalling snapshot: %w", err)
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		epic, err := tx.Bucket(snapshotsBucket).CreateBucketIfNotExists([]byte(snapshot.Epic))
+		if err != nil {
+			return err
+		}
+		return epic.Put(timeKey(snapshot.TakenAt), value)
+	})
+}
+
+// History returns the epic's snapshots taken in [from, to), oldest first. A
+// zero from or to leaves that end open. Per-issue states are only included
+// when withIssues is set.
+func (s *Store) History(epic string, from time.Time, to time.Time, withIssues bool) ([]Snapshot, error) {
+	snapshots := []Snapshot{}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(snapshotsBucket).Bucket([]byte(epic))
+		if bucket == nil {
+			return nil
+		}
+
+		cursor := bucket.Cursor()
+
+		key, value := cursor.First()
+		if !from.IsZero() {
+			key, value = cursor.Seek(timeKey(from))
+		}
+
+		for ; key != nil; key, value = cursor.Next() {
+			if !to.IsZero() && bytes.Compare(key, timeKey(to)) >= 0 {
+				break
+			}
+
+			var snapshot Snapshot
+			if err := json.Unmarshal(value, &snapshot); err != nil {
+				return fmt.Errorf("error unmarshalling snapshot: %w", err)
+			}
+			if !withIssues {
+				snapshot.Issues = nil
+			}
+			snapshots = append(snapshots, snapshot)
+		}
+
+		return nil
+	})
+
+	return snapshots, err
+}
+
+// Epics lists the epics that have at least one snapshot
+func (s *Store) Epics() ([]string, error) {
+	var epics []string
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(snapshotsBucket).ForEachBucket(func(key []byte) error {
+			epics = append(epics, string(key))
+			return nil
+		})
+	})
+
+	return epics, err
+}
+
+// timeKey encodes t as a key, clamped to the range keys can hold so that
+// bounds outside of it still compare correctly.
+func timeKey(t time.Time) []byte {
+	var nanos int64
+	switch {
+	case t.Before(minTime):
+		nanos = 0
+	case t.After(maxTime):
+		nanos = math.MaxInt64
+	default:
+		nanos = t.UnixNano()
+	}
+
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(nanos))
+	return key
+}
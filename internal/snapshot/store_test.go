@@ -0,0 +1,113 @@
+package snapshot
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+)
+
+func openTestStore(t *testing.T) *Store {
+	t.Helper()
+
+	store, err := Open(filepath.Join(t.TempDir(), "snapshots.db"))
+	if err != nil {
+		t.Fatalf("Open() error = %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func takenAt(snapshots []Snapshot) []time.Time {
+	times := make([]time.Time, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		times = append(times, snapshot.TakenAt)
+	}
+	return times
+}
+
+func TestStoreHistory(t *testing.T) {
+	store := openTestStore(t)
+
+	day := func(d int) time.Time {
+		return time.Date(2026, 10, d, 12, 0, 0, 0, time.UTC)
+	}
+
+	// Saved out of order, and one for another epic
+	for _, d := range []int{3, 1, 2} {
+		snapshot := Snapshot{Epic: "ABC-1", TakenAt: day(d), Issues: []IssueState{{Key: "ABC-2", Status: "To Do"}}}
+		if err := store.Save(snapshot); err != nil {
+			t.Fatalf("Save() error = %v", err)
+		}
+	}
+	if err := store.Save(Snapshot{Epic: "XYZ-1", TakenAt: day(2)}); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		from, to time.Time
+		want     []time.Time
+	}{
+		{"open range", time.Time{}, time.Time{}, []time.Time{day(1), day(2), day(3)}},
+		{"from is inclusive", day(2), time.Time{}, []time.Time{day(2), day(3)}},
+		{"to is exclusive", time.Time{}, day(3), []time.Time{day(1), day(2)}},
+		{"between", day(2), day(3), []time.Time{day(2)}},
+		{"empty range", day(2), day(2), []time.Time{}},
+		{"from before 1970", time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC), time.Time{}, []time.Time{day(1), day(2), day(3)}},
+		{"to before 1970", time.Time{}, time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC), []time.Time{}},
+		{"to past 2262", day(3), time.Date(3000, 1, 1, 0, 0, 0, 0, time.UTC), []time.Time{day(3)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			snapshots, err := store.History("ABC-1", tt.from, tt.to, false)
+			if err != nil {
+				t.Fatalf("History() error = %v", err)
+			}
+			if got := takenAt(snapshots); !slices.EqualFunc(got, tt.want, time.Time.Equal) {
+				t.Errorf("History() taken at %v, want %v", got, tt.want)
+			}
+			for _, snapshot := range snapshots {
+				if snapshot.Issues != nil {
+					t.Errorf("snapshot of %s has issues %v, want none without withIssues", snapshot.TakenAt, snapshot.Issues)
+				}
+			}
+		})
+	}
+
+	withIssues, err := store.History("ABC-1", time.Time{}, time.Time{}, true)
+	if err != nil {
+		t.Fatalf("History() error = %v", err)
+	}
+	for _, snapshot := range withIssues {
+		if len(snapshot.Issues) != 1 || snapshot.Issues[0].Key != "ABC-2" {
+			t.Errorf("snapshot of %s has issues %v, want ABC-2", snapshot.TakenAt, snapshot.Issues)
+		}
+	}
+
+	if unknown, err := store.History("NOPE-1", time.Time{}, time.Time{}, false); err != nil || len(unknown) != 0 {
+		t.Errorf("History() of an unknown epic = %v, %v, want no snapshots", unknown, err)
+	}
+
+	epics, err := store.Epics()
+	if err != nil {
+		t.Fatalf("Epics() error = %v", err)
+	}
+	if !slices.Equal(epics, []string{"ABC-1", "XYZ-1"}) {
+		t.Errorf("Epics() = %v, want [ABC-1 XYZ-1]", epics)
+	}
+}
+
+func TestStoreSaveOutOfRange(t *testing.T) {
+	store := openTestStore(t)
+
+	for _, at := range []time.Time{
+		time.Date(1969, 12, 31, 23, 59, 59, 0, time.UTC),
+		time.Date(2263, 1, 1, 0, 0, 0, 0, time.UTC),
+	} {
+		if err := store.Save(Snapshot{Epic: "ABC-1", TakenAt: at}); err == nil {
+			t.Errorf("Save() of a snapshot taken at %s succeeded, want an error", at)
+		}
+	}
+}
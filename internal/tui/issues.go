@@ -0,0 +1,139 @@
+package tui
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/model"
+	"github.com/Fuabioo/altalune/internal/report"
+
+	"github.com/charmbracelet/bubbles/table"
+)
+
+// issueSort is a column the issue list can be sorted by
+type issueSort int
+
+const (
+	sortKey issueSort = iota
+	sortStatus
+	sortPoints
+	sortAssignee
+)
+
+var sortNames = []string{"key", "status", "points", "assignee"}
+
+func (s issueSort) String() string {
+	return sortNames[s]
+}
+
+func (s issueSort) next() issueSort {
+	return (s + 1) % issueSort(len(sortNames))
+}
+
+// filterIssues keeps the issues whose key, summary, status, type or
+// assignee contain every word of the filter, ignoring case.
+func filterIssues(issues []*model.Ticket, filter string) []*model.Ticket {
+	words := strings.Fields(strings.ToLower(filter))
+	if len(words) == 0 {
+		return issues
+	}
+
+	var matches []*model.Ticket
+	for _, issue := range issues {
+		text := strings.ToLower(strings.Join([]string{
+			issue.Key,
+			issue.Fields.Summary,
+			issue.Fields.Status.Name,
+			issue.Fields.IssueType.Name,
+			issue.Fields.Assignee.DisplayName,
+		}, " "))
+
+		matched := true
+		for _, word := range words {
+			if !strings.Contains(text, word) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			matches = append(matches, issue)
+		}
+	}
+	return matches
+}
+
+// sortIssues sorts a copy of the issues by the column, falling back to the
+// key so the order is stable.
+func sortIssues(issues []*model.Ticket, by issueSort, descending bool, points jira.StoryPointFields) []*model.Ticket {
+	sorted := slices.Clone(issues)
+	slices.SortFunc(sorted, func(a, b *model.Ticket) int {
+		var order int
+		switch by {
+		case sortStatus:
+			order = cmp.Or(
+				cmp.Compare(report.ClassificationRank(a.Fields.StatusCategory.Key), report.ClassificationRank(b.Fields.StatusCategory.Key)),
+				strings.Compare(a.Fields.Status.Name, b.Fields.Status.Name),
+			)
+		case sortPoints:
+			order = cmp.Compare(points.Of(a), points.Of(b))
+		case sortAssignee:
+			order = strings.Compare(strings.ToLower(a.Fields.Assignee.DisplayName), strings.ToLower(b.Fields.Assignee.DisplayName))
+		}
+		order = cmp.Or(order, jira.CompareKeys(a.Key, b.Key))
+
+		if descending {
+			return -order
+		}
+		return order
+	})
+	return sorted
+}
+
+// issueColumns fits the summary column to the width left by the others
+func issueColumns(width int) []table.Column {
+	columns := []table.Column{
+		{Title: "Key", Width: 11},
+		{Title: "Type", Width: 10},
+		{Title: "Status", Width: 14},
+		{Title: "Points", Width: 6},
+		{Title: "Assignee", Width: 18},
+		{Title: "Summary"},
+	}
+
+	used := 0
+	for _, column := range columns {
+		// Each cell is padded by one column on both sides
+		used += column.Width + 2
+	}
+	columns[len(columns)-1].Width = max(width-used-2, 10)
+
+	return columns
+}
+
+func issueRows(issues []*model.Ticket, points jira.StoryPointFields) []table.Row {
+	rows := make([]table.Row, 0, len(issues))
+	for _, issue := range issues {
+		estimate := ""
+		if value := points.Of(issue); value > 0 {
+			estimate = strconv.FormatFloat(value, 'f', -1, 64)
+		}
+
+		assignee := issue.Fields.Assignee.DisplayName
+		if assignee == "" {
+			assignee = "Unassigned"
+		}
+
+		rows = append(rows, table.Row{
+			issue.Key,
+			issue.Fields.IssueType.Name,
+			issue.Fields.Status.Name,
+			estimate,
+			assignee,
+			issue.Fields.Summary,
+		})
+	}
+	return rows
+}
@@ -0,0 +1,420 @@
+// Package tui is the terminal dashboard: a list of saved epics, and for each
+// one its progress, a filterable issue list and its dependency tree.
+package tui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/Fuabioo/altalune/internal/epics"
+	"github.com/Fuabioo/altalune/internal/jira"
+	"github.com/Fuabioo/altalune/internal/report"
+
+	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+type (
+	screen int
+	tab    int
+)
+
+const (
+	screenEpics screen = iota
+	screenEpic
+)
+
+const (
+	tabIssues tab = iota
+	tabGraph
+)
+
+var (
+	titleStyle     = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#c6a0f6"))
+	mutedStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#6e738d"))
+	errorStyle     = lipgloss.NewStyle().Foreground(lipgloss.Color("#ed8796"))
+	selectedStyle  = lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("#8aadf4"))
+	activeTabStyle = lipgloss.NewStyle().Bold(true).Padding(0, 1).
+			Foreground(lipgloss.Color("#24273a")).
+			Background(lipgloss.Color("#c6a0f6"))
+	tabStyle = lipgloss.NewStyle().Padding(0, 1).Foreground(lipgloss.Color("#6e738d"))
+)
+
+type (
+	// epicData is a loaded epic with everything its screen shows
+	epicData struct {
+		result epics.Result
+		points jira.StoryPointFields
+		report report.Report
+		tree   string
+	}
+	epicLoaded struct {
+		key  string
+		data epicData
+		err  error
+	}
+
+	Model struct {
+		ctx    context.Context
+		cancel context.CancelFunc
+		loader *epics.Loader
+		hops   int
+		saved  []string
+		cursor int
+
+		screen screen
+		tab    tab
+		width  int
+		height int
+
+		key     string
+		loading bool
+		err     error
+		epic    *epicData
+
+		issues     table.Model
+		filter     textinput.Model
+		filtering  bool
+		sortBy     issueSort
+		descending bool
+		graph      viewport.Model
+	}
+)
+
+// New lists the saved epics, loading them through loader. hops follows
+// links that many issues away from each epic, as in the web interface.
+// Loads are cancelled along with ctx, or when the dashboard quits.
+func New(ctx context.Context, loader *epics.Loader, saved []string, hops int) Model {
+	ctx, cancel := context.WithCancel(ctx)
+
+	filter := textinput.New()
+	filter.Prompt = "/ "
+	filter.Placeholder = "filter issues"
+
+	styles := table.DefaultStyles()
+	styles.Header = styles.Header.Bold(true).
+		BorderStyle(lipgloss.NormalBorder()).
+		BorderBottom(true).
+		BorderForeground(lipgloss.Color("#6e738d"))
+	styles.Selected = styles.Selected.
+		Foreground(lipgloss.Color("#24273a")).
+		Background(lipgloss.Color("#8aadf4"))
+
+	return Model{
+		ctx:    ctx,
+		cancel: cancel,
+		loader: loader,
+		hops:   hops,
+		saved:  saved,
+		filter: filter,
+		issues: table.New(table.WithFocused(true), table.WithStyles(styles)),
+		graph:  viewport.New(0, 0),
+	}
+}
+
+func (m Model) Init() tea.Cmd {
+	return nil
+}
+
+// load fetches the epic through the loader's cache, bypassing it when
+// refresh is set.
+func (m Model) load(key string, refresh bool) tea.Cmd {
+	ctx, loader, hops := m.ctx, m.loader, m.hops
+
+	return func() tea.Msg {
+		points := loader.Points(ctx)
+
+		result, linked, err := loader.EpicWithLinks(ctx, epics.Query{
+			Key:     key,
+			Fields:  jira.DefaultFields(points),
+			Refresh: refresh,
+		}, hops)
+		if err != nil {
+			return epicLoaded{key: key, err: err}
+		}
+
+		return epicLoaded{key: key, data: epicData{
+			result: result,
+			points: points,
+			report: report.New(key, result.Issues, points, linked, result.FetchedAt),
+			tree:   report.DependencyTree(jira.BuildGraph(result.Issues, key, points, linked), key),
+		}}
+	}
+}
+
+// quit cancels the loads still running and exits
+func (m Model) quit() (tea.Model, tea.Cmd) {
+	m.cancel()
+	return m, tea.Quit
+}
+
+func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		m.width, m.height = msg.Width, msg.Height
+		m.layout()
+		return m, nil
+
+	case epicLoaded:
+		// A slow load of an epic that was since left is dropped
+		if msg.key != m.key {
+			return m, nil
+		}
+		m.loading = false
+		m.err = msg.err
+		if msg.err == nil {
+			m.epic = &msg.data
+			m.graph.SetContent(msg.data.tree)
+			m.refreshRows()
+		}
+		m.layout()
+		return m, nil
+
+	case tea.KeyMsg:
+		if msg.String() == "ctrl+c" {
+			return m.quit()
+		}
+		if m.screen == screenEpics {
+			return m.updateEpics(msg)
+		}
+		return m.updateEpic(msg)
+	}
+
+	return m, nil
+}
+
+func (m Model) updateEpics(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "q", "esc":
+		return m.quit()
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, max(len(m.saved)-1, 0))
+	case "enter":
+		if len(m.saved) == 0 {
+			return m, nil
+		}
+		m.screen = screenEpic
+		m.tab = tabIssues
+		m.key = m.saved[m.cursor]
+		m.epic = nil
+		m.err = nil
+		m.loading = true
+		m.filter.SetValue("")
+		m.issues.SetCursor(0)
+		m.graph.GotoTop()
+		return m, m.load(m.key, false)
+	}
+	return m, nil
+}
+
+func (m Model) updateEpic(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.filtering {
+		switch msg.String() {
+		case "esc":
+			m.filter.SetValue("")
+			fallthrough
+		case "enter":
+			m.filtering = false
+			m.filter.Blur()
+			m.issues.Focus()
+			m.refreshRows()
+			m.layout()
+			return m, nil
+		}
+
+		var cmd tea.Cmd
+		m.filter, cmd = m.filter.Update(msg)
+		m.refreshRows()
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "q":
+		return m.quit()
+	case "esc", "backspace":
+		m.screen = screenEpics
+		m.key = ""
+		m.loading = false
+		return m, nil
+	case "tab":
+		m.tab = (m.tab + 1) % 2
+		return m, nil
+	case "r":
+		if m.loading {
+			return m, nil
+		}
+		m.loading = true
+		return m, m.load(m.key, true)
+	}
+
+	if m.epic == nil {
+		return m, nil
+	}
+
+	if m.tab == tabGraph {
+		var cmd tea.Cmd
+		m.graph, cmd = m.graph.Update(msg)
+		return m, cmd
+	}
+
+	switch msg.String() {
+	case "/":
+		m.filtering = true
+		m.issues.Blur()
+		m.layout()
+		return m, m.filter.Focus()
+	case "s":
+		m.sortBy = m.sortBy.next()
+		m.refreshRows()
+		return m, nil
+	case "S":
+		m.descending = !m.descending
+		m.refreshRows()
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.issues, cmd = m.issues.Update(msg)
+	return m, cmd
+}
+
+// refreshRows filters and sorts the epic's issues into the table
+func (m *Model) refreshRows() {
+	if m.epic == nil {
+		return
+	}
+
+	issues := filterIssues(m.epic.result.Issues, m.filter.Value())
+	issues = sortIssues(issues, m.sortBy, m.descending, m.epic.points)
+	m.issues.SetRows(issueRows(issues, m.epic.points))
+	if m.issues.Cursor() >= len(issues) {
+		m.issues.SetCursor(max(len(issues)-1, 0))
+	}
+}
+
+// layout sizes the issue table and graph to what the header leaves
+func (m *Model) layout() {
+	if m.width == 0 {
+		return
+	}
+
+	body := max(m.height-lipgloss.Height(m.header())-3, 3)
+	if m.filtering || m.filter.Value() != "" {
+		body--
+	}
+
+	m.issues.SetColumns(issueColumns(m.width))
+	m.issues.SetWidth(m.width)
+	m.issues.SetHeight(body)
+	m.graph.Width = m.width
+	m.graph.Height = body
+}
+
+func (m Model) View() string {
+	if m.screen == screenEpics {
+		return m.epicsView()
+	}
+	return m.epicView()
+}
+
+func (m Model) epicsView() string {
+	lines := []string{titleStyle.Render("Altalune") + mutedStyle.Render(" · saved epics"), ""}
+
+	if len(m.saved) == 0 {
+		lines = append(lines,
+			"No saved epics yet.",
+			mutedStyle.Render("List them under epics in config.yaml, or pass them to altalune tui."),
+		)
+	}
+
+	for i, key := range m.saved {
+		if i == m.cursor {
+			lines = append(lines, selectedStyle.Render("› "+key))
+		} else {
+			lines = append(lines, "  "+key)
+		}
+	}
+
+	lines = append(lines, "", mutedStyle.Render("↑/↓ move · enter open · q quit"))
+	return strings.Join(lines, "\n")
+}
+
+// header is the epic's title, progress and tabs
+func (m Model) header() string {
+	title := titleStyle.Render(m.key)
+	if m.epic != nil && m.epic.report.Summary != "" {
+		title += " " + lipgloss.NewStyle().Bold(true).Render(m.epic.report.Summary)
+	}
+
+	lines := []string{title}
+	switch {
+	case m.epic != nil:
+		status := fmt.Sprintf("Fetched %s (%s)",
+			m.epic.result.FetchedAt.Local().Format("15:04:05"),
+			strings.ToLower(string(m.epic.result.Status)),
+		)
+		if m.loading {
+			status += " · refreshing…"
+		}
+		lines = append(lines,
+			mutedStyle.Render(status),
+			"",
+			report.Progress(m.epic.report, min(max(m.width-50, 10), 40)),
+		)
+	case m.loading:
+		lines = append(lines, mutedStyle.Render("Loading…"))
+	}
+
+	tabs := []string{"Issues", "Graph"}
+	for i, name := range tabs {
+		if tab(i) == m.tab {
+			tabs[i] = activeTabStyle.Render(name)
+		} else {
+			tabs[i] = tabStyle.Render(name)
+		}
+	}
+	lines = append(lines, "", strings.Join(tabs, " "))
+
+	return strings.Join(lines, "\n")
+}
+
+func (m Model) epicView() string {
+	sections := []string{m.header()}
+
+	switch {
+	case m.err != nil:
+		sections = append(sections, errorStyle.Render("Could not load the epic: "+m.err.Error()))
+	case m.epic == nil:
+	case m.tab == tabGraph:
+		sections = append(sections, m.graph.View())
+	default:
+		if m.filtering || m.filter.Value() != "" {
+			sections = append(sections, m.filter.View())
+		}
+		sections = append(sections, m.issues.View())
+	}
+
+	sections = append(sections, mutedStyle.Render(m.help()))
+	return strings.Join(sections, "\n")
+}
+
+func (m Model) help() string {
+	switch {
+	case m.filtering:
+		return "enter apply · esc clear"
+	case m.tab == tabGraph:
+		return "↑/↓ scroll · tab issues · r refresh · esc back · q quit"
+	default:
+		order := "ascending"
+		if m.descending {
+			order = "descending"
+		}
+		return fmt.Sprintf("/ filter · s sort (%s) · S %s · tab graph · r refresh · esc back · q quit", m.sortBy, order)
+	}
+}
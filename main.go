@@ -9,6 +9,9 @@ import (
 //go:embed frontend/dist
 var staticFiles embed.FS
 
+// version is set at release time with -ldflags "-X main.version=..."
+var version string
+
 func main() {
-	cmd.Execute(staticFiles)
+	cmd.Execute(staticFiles, version)
 }
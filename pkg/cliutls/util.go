@@ -3,15 +3,23 @@ package cliutils
 import (
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/log"
 	"github.com/joho/godotenv"
 )
 
-func LoadEnvFiles() {
+// EnvFile is an env file that was loaded and the variables it set
+type EnvFile struct {
+	Path      string
+	Variables []string
+}
 
-	// Try multiple locations in order of preference
+// LoadEnvFiles loads every env file found, in order of preference: a
+// variable set by the environment, or by a file loaded before, is left as
+// is.
+func LoadEnvFiles() []EnvFile {
 	cwd, _ := os.Getwd()
 	homeDir, _ := os.UserHomeDir()
 
@@ -22,17 +30,37 @@ func LoadEnvFiles() {
 		filepath.Join(homeDir, ".altalune.env"),
 	}
 
-	// Load the first available .env file
-	for _, envFile := range envFiles {
-		if _, err := os.Stat(envFile); err == nil {
-			if err := godotenv.Load(envFile); err != nil {
-				log.Warn("Could not load env file", "file", envFile, "err", err)
-			} else {
-				log.Debug("Loaded environment from", "file", envFile)
-				break
+	var loaded []EnvFile
+	for i, envFile := range envFiles {
+		// The working directory may be the home directory
+		if slices.Contains(envFiles[:i], envFile) {
+			continue
+		}
+		if _, err := os.Stat(envFile); err != nil {
+			continue
+		}
+
+		values, err := godotenv.Read(envFile)
+		if err != nil {
+			log.Warn("Could not load env file", "file", envFile, "err", err)
+			continue
+		}
+
+		file := EnvFile{Path: envFile}
+		for name, value := range values {
+			if _, ok := os.LookupEnv(name); ok {
+				continue
 			}
+			os.Setenv(name, value)
+			file.Variables = append(file.Variables, name)
 		}
+		slices.Sort(file.Variables)
+
+		log.Debug("Loaded environment from", "file", envFile, "variables", len(file.Variables))
+		loaded = append(loaded, file)
 	}
+
+	return loaded
 }
 
 func MaskToken(token string) string {
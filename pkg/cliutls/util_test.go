@@ -0,0 +1,78 @@
+package cliutils
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestLoadEnvFiles(t *testing.T) {
+	home := t.TempDir()
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", home)
+	cwd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	write := func(path, content string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(filepath.Join(cwd, ".env"), "ALTALUNE_TEST_A=env\nALTALUNE_TEST_B=env\n")
+	write(filepath.Join(cwd, ".env.local"), "ALTALUNE_TEST_A=local\nALTALUNE_TEST_C=local\n")
+	write(filepath.Join(home, ".env"), "ALTALUNE_TEST_C=home\nALTALUNE_TEST_D=home\n")
+	write(filepath.Join(home, ".altalune.env"), "ALTALUNE_TEST_D=altalune\nALTALUNE_TEST_E=altalune\n")
+
+	// Restored once the test is done, E is set by the environment
+	for _, name := range []string{"ALTALUNE_TEST_A", "ALTALUNE_TEST_B", "ALTALUNE_TEST_C", "ALTALUNE_TEST_D"} {
+		t.Setenv(name, "")
+		os.Unsetenv(name)
+	}
+	t.Setenv("ALTALUNE_TEST_E", "environment")
+
+	got := LoadEnvFiles()
+	want := []EnvFile{
+		{Path: filepath.Join(cwd, ".env"), Variables: []string{"ALTALUNE_TEST_A", "ALTALUNE_TEST_B"}},
+		{Path: filepath.Join(cwd, ".env.local"), Variables: []string{"ALTALUNE_TEST_C"}},
+		{Path: filepath.Join(home, ".env"), Variables: []string{"ALTALUNE_TEST_D"}},
+		{Path: filepath.Join(home, ".altalune.env")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadEnvFiles() = %+v, want %+v", got, want)
+	}
+
+	for name, value := range map[string]string{
+		"ALTALUNE_TEST_A": "env",
+		"ALTALUNE_TEST_B": "env",
+		"ALTALUNE_TEST_C": "local",
+		"ALTALUNE_TEST_D": "home",
+		"ALTALUNE_TEST_E": "environment",
+	} {
+		if got := os.Getenv(name); got != value {
+			t.Errorf("%s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestLoadEnvFilesFromHome(t *testing.T) {
+	home := t.TempDir()
+	t.Chdir(home)
+	t.Setenv("HOME", home)
+
+	if err := os.WriteFile(filepath.Join(home, ".env"), []byte("ALTALUNE_TEST_A=home\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ALTALUNE_TEST_A", "")
+	os.Unsetenv("ALTALUNE_TEST_A")
+
+	// The working directory's .env is home's, loaded once
+	got := LoadEnvFiles()
+	want := []EnvFile{{Path: filepath.Join(home, ".env"), Variables: []string{"ALTALUNE_TEST_A"}}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("LoadEnvFiles() = %+v, want %+v", got, want)
+	}
+}